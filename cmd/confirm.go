@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+var yes bool
+
+// ErrAborted is returned if the user did not confirm an operation.
+var ErrAborted = errors.New("operation aborted")
+
+// confirm asks the user to confirm a destructive operation on
+// the terminal, unless it has already been confirmed via the
+// "--yes" command line flag.
+func confirm(prompt string) error {
+	if yes {
+		return nil
+	}
+
+	fmt.Fprintf(os.Stderr, "%s [y/N]: ", prompt)
+
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+
+	// Anything but an explicit confirmation aborts the operation.
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return nil
+	default:
+		return ErrAborted
+	}
+}
@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	nfmt "github.com/nicklasfrahm/netadm/pkg/fmt"
+	"github.com/nicklasfrahm/netadm/pkg/nsdp"
+	"github.com/spf13/cobra"
+)
+
+var decodeCmd = &cobra.Command{
+	Use:   "decode <hex|file>",
+	Short: "Decode a raw message",
+	Long: `Decode a raw message and print its header
+and all of its records.
+
+The message may be passed as hexadecimal
+string, in which spaces and colons are
+ignored, or as a file that contains the
+message in its binary or hexadecimal form.
+Pass "-" to read the message from stdin.
+
+Every record is printed with its raw and
+its decoded value, which is useful to
+reverse-engineer unknown records. If the
+message is malformed, the header and the
+records before the malformed part are
+printed before the error is reported.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		data, err := readMessage(args[0])
+		if err != nil {
+			return err
+		}
+
+		// Print the header and the records that were decoded before
+		// reporting an error, as they help to find the malformed part.
+		msg := new(nsdp.Message)
+		err = msg.UnmarshalBinary(data)
+		if err == nil || len(data) >= binary.Size(msg.Header) {
+			nfmt.Message(os.Stdout, msg)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to decode message after %d records: %w", len(msg.Records), err)
+		}
+
+		return nil
+	},
+}
+
+// readMessage reads the raw bytes of a message from a hexadecimal
+// string, from a file or from stdin if the argument is "-".
+func readMessage(arg string) ([]byte, error) {
+	var data []byte
+	var err error
+	switch {
+	case arg == "-":
+		data, err = io.ReadAll(os.Stdin)
+	case fileExists(arg):
+		data, err = os.ReadFile(arg)
+	default:
+		return decodeHex(arg)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Files may also contain the message in its hexadecimal form.
+	if raw, err := decodeHex(string(data)); err == nil {
+		return raw, nil
+	}
+
+	return data, nil
+}
+
+// decodeHex decodes a hexadecimal string while ignoring whitespace and colons.
+func decodeHex(s string) ([]byte, error) {
+	s = strings.NewReplacer(" ", "", ":", "", "\n", "", "\r", "", "\t", "").Replace(s)
+	s = strings.TrimPrefix(s, "0x")
+
+	data, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid hexadecimal message: %w", err)
+	}
+
+	return data, nil
+}
+
+// fileExists returns true if the path exists.
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func init() {
+	rootCmd.AddCommand(decodeCmd)
+}
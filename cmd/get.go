@@ -8,6 +8,8 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var getRaw bool
+
 var getCmd = &cobra.Command{
 	Use:   "get <device> [key ...]",
 	Short: "Read configuration keys",
@@ -15,24 +17,41 @@ var getCmd = &cobra.Command{
 list of specified configuration keys.
 
 You may run the "keys" subcommand
-to see a list of available keys.`,
+to see a list of available keys.
+
+Records of unknown types, which some
+firmware versions return, are skipped.
+If you pass the "--raw" flag, they are
+printed as hexadecimal values.`,
 	Args: cobra.MinimumNArgs(2),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		id := args[0]
 		keys := args[1:]
 
-		devices, err := nsdp.Get(id, keys,
-			nsdp.WithInterfaceName(interfaceName),
-			nsdp.WithRetries(retries),
-			nsdp.WithTimeout(timeout),
-		)
+		results, err := nsdp.GetResults(id, keys, commonOptions()...)
 		if err != nil {
 			return err
 		}
 
-		// Print results.
+		// Print the results of all devices that succeeded.
+		devices := make([]nsdp.Device, 0, len(results))
+		for _, result := range results {
+			if result.Err == nil {
+				devices = append(devices, result.Device)
+			}
+		}
 		fmt.Table(os.Stdout, devices, keys)
 
+		if getRaw {
+			os.Stdout.WriteString("\n")
+			fmt.Raw(os.Stdout, devices)
+		}
+
+		// Summarize the devices that failed.
+		if fmt.Failures(os.Stderr, results) > 0 {
+			return nsdp.ErrDevicesFailed
+		}
+
 		return nil
 	},
 }
@@ -40,6 +59,7 @@ to see a list of available keys.`,
 func init() {
 	getCmd.Flags().StringVarP(&interfaceName, "interface", "i", "", "name of the interface to use")
 	getCmd.MarkFlagRequired("interface")
+	getCmd.Flags().BoolVar(&getRaw, "raw", false, "print unknown records as hexadecimal values")
 
 	rootCmd.AddCommand(getCmd)
 }
@@ -0,0 +1,192 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"strings"
+
+	"github.com/nicklasfrahm/netadm/pkg/fmt"
+	"github.com/nicklasfrahm/netadm/pkg/nsdp"
+	"github.com/spf13/cobra"
+)
+
+var igmpVLAN uint16
+
+var igmpCmd = &cobra.Command{
+	Use:   "igmp",
+	Short: "Change IGMP snooping settings",
+	Long: `Change the IGMP snooping and multicast
+filtering settings of a network device.
+
+Without IGMP snooping and the filtering
+of unknown multicast traffic, multicast
+traffic is flooded to all ports.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+		os.Exit(0)
+	},
+	SilenceUsage: true,
+}
+
+var igmpListCmd = &cobra.Command{
+	Use:   "list <device>",
+	Short: "List the IGMP snooping settings",
+	Long:  `List the IGMP snooping and multicast filtering settings of a device.`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return printIGMP(args[0], commonOptions()...)
+	},
+}
+
+var igmpEnableCmd = &cobra.Command{
+	Use:   "enable --vlan <vlan> <device>",
+	Short: "Enable IGMP snooping",
+	Long:  `Enable IGMP snooping for the specified VLAN of a device.`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return updateIGMP(args[0], func(id string, opts ...nsdp.Option) ([]nsdp.Device, error) {
+			return nsdp.EnableIGMPSnooping(id, igmpVLAN, opts...)
+		})
+	},
+}
+
+var igmpDisableCmd = &cobra.Command{
+	Use:   "disable <device>",
+	Short: "Disable IGMP snooping",
+	Long:  `Disable IGMP snooping on a device.`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return updateIGMP(args[0], nsdp.DisableIGMPSnooping)
+	},
+}
+
+var igmpFilterCmd = &cobra.Command{
+	Use:   "filter <on|off> <device>",
+	Short: "Toggle the filtering of unknown multicast traffic",
+	Long: `Toggle the filtering of unknown multicast traffic.
+
+If enabled, multicast traffic is only
+forwarded to the ports that joined the
+multicast group.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		enabled, err := parseToggle(args[0])
+		if err != nil {
+			return err
+		}
+
+		return updateIGMP(args[1], func(id string, opts ...nsdp.Option) ([]nsdp.Device, error) {
+			return nsdp.SetMulticastFilter(id, enabled, opts...)
+		})
+	},
+}
+
+var igmpValidationCmd = &cobra.Command{
+	Use:   "validation <on|off> <device>",
+	Short: "Toggle the validation of IGMPv3 headers",
+	Long:  `Toggle the validation of IGMPv3 headers.`,
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		enabled, err := parseToggle(args[0])
+		if err != nil {
+			return err
+		}
+
+		return updateIGMP(args[1], func(id string, opts ...nsdp.Option) ([]nsdp.Device, error) {
+			return nsdp.SetIGMPHeaderValidation(id, enabled, opts...)
+		})
+	},
+}
+
+var igmpRoutersCmd = &cobra.Command{
+	Use:   "routers <device> <ports|none>",
+	Short: "Change the static IGMP router ports",
+	Long: `Change the static IGMP router ports.
+
+Multicast reports are always forwarded
+to the IGMP router ports. You may specify
+the ports as a comma-separated list, which
+may also contain port ranges, such as
+"1,3-5". Use "none" to remove all static
+IGMP router ports.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ports := []uint8{}
+		if !strings.EqualFold(args[1], "none") {
+			var err error
+			if ports, err = parsePorts(args[1]); err != nil {
+				return err
+			}
+		}
+
+		return updateIGMP(args[0], func(id string, opts ...nsdp.Option) ([]nsdp.Device, error) {
+			return nsdp.SetIGMPRouterPorts(id, ports, opts...)
+		})
+	},
+}
+
+// updateIGMP applies a change to the IGMP settings of
+// a device and prints the resulting settings afterwards.
+func updateIGMP(id string, update func(id string, options ...nsdp.Option) ([]nsdp.Device, error)) error {
+	if id == "all" {
+		return errors.New("writing to all devices is not supported")
+	}
+
+	opts := append(commonOptions(), nsdp.WithPassword(password))
+
+	if _, err := update(id, opts...); err != nil {
+		return err
+	}
+
+	return printIGMP(id, opts...)
+}
+
+// printIGMP reads the IGMP settings of a device and prints them as table.
+func printIGMP(id string, options ...nsdp.Option) error {
+	keys := []string{"mac", "igmpsnoopingvlan", "multicastfilter", "igmpheadervalidation", "igmprouterports"}
+
+	devices, err := nsdp.Get(id, keys, options...)
+	if err != nil {
+		return err
+	}
+
+	// Print results.
+	fmt.Table(os.Stdout, devices, keys)
+
+	return nil
+}
+
+// parseToggle parses the state of a feature toggle.
+func parseToggle(state string) (bool, error) {
+	switch strings.ToLower(state) {
+	case "on", "enable", "enabled", "true":
+		return true, nil
+	case "off", "disable", "disabled", "false":
+		return false, nil
+	default:
+		return false, errors.New(`state must either be "on" or "off"`)
+	}
+}
+
+func init() {
+	igmpListCmd.Flags().StringVarP(&interfaceName, "interface", "i", "", "name of the interface to use")
+	igmpListCmd.MarkFlagRequired("interface")
+
+	for _, cmd := range []*cobra.Command{igmpEnableCmd, igmpDisableCmd, igmpFilterCmd, igmpValidationCmd, igmpRoutersCmd} {
+		cmd.Flags().StringVarP(&interfaceName, "interface", "i", "", "name of the interface to use")
+		cmd.MarkFlagRequired("interface")
+		cmd.Flags().StringVarP(&password, "password", "p", "", "password to use for authentication")
+		cmd.MarkFlagRequired("password")
+	}
+	igmpEnableCmd.Flags().Uint16Var(&igmpVLAN, "vlan", 0, "VLAN to enable IGMP snooping for")
+	igmpEnableCmd.MarkFlagRequired("vlan")
+
+	igmpCmd.AddCommand(igmpListCmd)
+	igmpCmd.AddCommand(igmpEnableCmd)
+	igmpCmd.AddCommand(igmpDisableCmd)
+	igmpCmd.AddCommand(igmpFilterCmd)
+	igmpCmd.AddCommand(igmpValidationCmd)
+	igmpCmd.AddCommand(igmpRoutersCmd)
+
+	rootCmd.AddCommand(igmpCmd)
+}
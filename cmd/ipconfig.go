@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"errors"
+	"net"
+	"os"
+	"time"
+
+	"github.com/nicklasfrahm/netadm/pkg/fmt"
+	"github.com/nicklasfrahm/netadm/pkg/nsdp"
+	"github.com/spf13/cobra"
+)
+
+var ipconfigIP string
+var ipconfigMask string
+var ipconfigGateway string
+var ipconfigDHCP bool
+
+var ipconfigCmd = &cobra.Command{
+	Use:   "ipconfig <device>",
+	Short: "Change the IP configuration of a device",
+	Long: `Change the IP configuration of a device.
+
+You may either assign a static address
+via the "--ip", "--mask" and "--gw" flags
+or enable DHCP via the "--dhcp" flag. The
+address, netmask and gateway are checked
+for consistency and written in a single
+request to prevent partial changes.
+
+Afterwards the device is rediscovered via
+its MAC to report its new address.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id := args[0]
+		if id == "all" {
+			return errors.New("writing to all devices is not supported")
+		}
+
+		config, err := parseIPConfig()
+		if err != nil {
+			return err
+		}
+
+		devices, err := nsdp.SetIPConfig(id, *config, append(commonOptions(), nsdp.WithPassword(password))...)
+		if err != nil {
+			return err
+		}
+
+		keys := []string{"name", "mac", "ip", "netmask", "gateway", "dhcp"}
+		devices, err = waitForIPConfig(devices[0].MAC, config, keys)
+		if err != nil {
+			return err
+		}
+
+		// Print results.
+		fmt.Table(os.Stdout, devices, keys)
+
+		return nil
+	},
+}
+
+// parseIPConfig creates the IP configuration from the flags.
+func parseIPConfig() (*nsdp.IPConfig, error) {
+	static := ipconfigIP != "" || ipconfigMask != "" || ipconfigGateway != ""
+	if ipconfigDHCP && static {
+		return nil, errors.New(`flag "--dhcp" can't be combined with a static address`)
+	}
+
+	if ipconfigDHCP {
+		return &nsdp.IPConfig{DHCP: true}, nil
+	}
+
+	if ipconfigIP == "" || ipconfigMask == "" {
+		return nil, errors.New(`flags "--ip" and "--mask" or flag "--dhcp" must be set`)
+	}
+
+	config := &nsdp.IPConfig{
+		IP:      net.ParseIP(ipconfigIP),
+		Netmask: net.ParseIP(ipconfigMask),
+	}
+	if config.IP == nil {
+		return nil, errors.New("invalid IP address: " + ipconfigIP)
+	}
+	if config.Netmask == nil {
+		return nil, errors.New("invalid netmask: " + ipconfigMask)
+	}
+
+	if ipconfigGateway != "" {
+		config.Gateway = net.ParseIP(ipconfigGateway)
+		if config.Gateway == nil {
+			return nil, errors.New("invalid gateway: " + ipconfigGateway)
+		}
+	}
+
+	return config, config.Validate()
+}
+
+// waitForIPConfig waits until a device reports the given IP
+// configuration. The device is identified via its MAC as its
+// IP address changes.
+func waitForIPConfig(mac net.HardwareAddr, config *nsdp.IPConfig, keys []string) ([]nsdp.Device, error) {
+	deadline := time.Now().Add(waitTimeout)
+
+	for time.Now().Before(deadline) {
+		devices, err := nsdp.Get(mac.String(), keys, commonOptions()...)
+		if err != nil && !errors.Is(err, nsdp.ErrNoDevicesFound) {
+			return nil, err
+		}
+
+		if err == nil {
+			device := devices[0]
+			if config.DHCP && device.DHCP || !config.DHCP && !device.DHCP && device.IP.Equal(config.IP) {
+				return devices, nil
+			}
+		}
+
+		time.Sleep(pollInterval)
+	}
+
+	return nil, ErrWaitTimeout
+}
+
+func init() {
+	ipconfigCmd.Flags().StringVarP(&interfaceName, "interface", "i", "", "name of the interface to use")
+	ipconfigCmd.MarkFlagRequired("interface")
+	ipconfigCmd.Flags().StringVarP(&password, "password", "p", "", "password to use for authentication")
+	ipconfigCmd.MarkFlagRequired("password")
+	ipconfigCmd.Flags().StringVar(&ipconfigIP, "ip", "", "static IP address of the device")
+	ipconfigCmd.Flags().StringVar(&ipconfigMask, "mask", "", "netmask of the device")
+	ipconfigCmd.Flags().StringVar(&ipconfigGateway, "gw", "", "gateway of the device")
+	ipconfigCmd.Flags().BoolVar(&ipconfigDHCP, "dhcp", false, "obtain the IP configuration via DHCP")
+	ipconfigCmd.Flags().DurationVar(&waitTimeout, "wait-timeout", 2*time.Minute, "maximum time to wait for the device")
+
+	rootCmd.AddCommand(ipconfigCmd)
+}
@@ -11,12 +11,29 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var keysModel string
+var keysFirmware string
+
 var keysCmd = &cobra.Command{
 	Use:   "keys",
 	Short: "List available configuration keys",
 	Long: `A command that allows you to list all
-available configuration keys.`,
+available configuration keys.
+
+If you pass the "--model" flag, only the
+keys supported by the model are listed.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		var capability *nsdp.Capability
+		if keysModel != "" {
+			capability = nsdp.LookupCapability(keysModel, keysFirmware)
+			if capability == nil {
+				fmt.Fprintf(os.Stderr, "Model %s is unknown, listing all keys.\n", keysModel)
+			} else {
+				fmt.Fprintf(os.Stderr, "Model %s has %d ports, a PoE budget of %dW and uses %s password encryption.\n",
+					capability.Model, capability.PortCount, capability.PoEBudget, strings.ToLower(capability.PasswordEncryption.String()))
+			}
+		}
+
 		// Sort keys by RecordTypeID to get consistent results.
 		ids := make([]int, 0, len(nsdp.RecordTypeByName))
 		for _, rt := range nsdp.RecordTypeByName {
@@ -32,7 +49,11 @@ available configuration keys.`,
 		for _, id := range ids {
 			// Fetch record type by ID.
 			rt := nsdp.RecordTypeByID[nsdp.RecordTypeID(id)]
-			fmt.Fprintf(w, "0x%04X\t%s\t%v\n", rt.ID, strings.ToLower(rt.Name), rt.Example)
+			if capability != nil && !capability.Supports(rt) {
+				continue
+			}
+
+			fmt.Fprintf(w, "%s\t%s\t%v\n", rt.ID, strings.ToLower(rt.Name), rt.Example)
 		}
 
 		return w.Flush()
@@ -40,5 +61,8 @@ available configuration keys.`,
 }
 
 func init() {
+	keysCmd.Flags().StringVar(&keysModel, "model", "", "only list keys supported by the model")
+	keysCmd.Flags().StringVar(&keysFirmware, "firmware", "", "firmware version of the model")
+
 	rootCmd.AddCommand(keysCmd)
 }
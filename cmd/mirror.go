@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+
+	"github.com/nicklasfrahm/netadm/pkg/fmt"
+	"github.com/nicklasfrahm/netadm/pkg/nsdp"
+	"github.com/spf13/cobra"
+)
+
+var mirrorTo uint8
+var mirrorFrom string
+
+var mirrorCmd = &cobra.Command{
+	Use:   "mirror",
+	Short: "Change port mirroring settings",
+	Long: `Change the port mirroring settings of a
+network device to capture the traffic
+of one or multiple ports.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+		os.Exit(0)
+	},
+	SilenceUsage: true,
+}
+
+var mirrorSetCmd = &cobra.Command{
+	Use:   "set <device> --to <port> --from <ports>",
+	Short: "Mirror the traffic of ports to another port",
+	Long: `Mirror the traffic of the source ports
+to the destination port.
+
+You may specify the source ports as a
+comma-separated list, which may also
+contain port ranges, such as "1,3-5".
+The destination port can't be one of
+the source ports.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id := args[0]
+		if id == "all" {
+			return errors.New("writing to all devices is not supported")
+		}
+
+		sources, err := parsePorts(mirrorFrom)
+		if err != nil {
+			return err
+		}
+
+		opts := append(commonOptions(), nsdp.WithPassword(password))
+
+		mirroring := nsdp.PortMirroring{
+			Destination: mirrorTo,
+			Sources:     sources,
+		}
+		if _, err := nsdp.SetPortMirroring(id, mirroring, opts...); err != nil {
+			return err
+		}
+
+		return printPortMirroring(id, opts...)
+	},
+}
+
+var mirrorOffCmd = &cobra.Command{
+	Use:   "off <device>",
+	Short: "Disable port mirroring",
+	Long:  `Disable the port mirroring of a device.`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id := args[0]
+		if id == "all" {
+			return errors.New("writing to all devices is not supported")
+		}
+
+		opts := append(commonOptions(), nsdp.WithPassword(password))
+
+		if _, err := nsdp.DisablePortMirroring(id, opts...); err != nil {
+			return err
+		}
+
+		return printPortMirroring(id, opts...)
+	},
+}
+
+// printPortMirroring reads the port mirroring configuration
+// of a device and prints it as table.
+func printPortMirroring(id string, options ...nsdp.Option) error {
+	keys := []string{"mac", "portmirroring"}
+
+	devices, err := nsdp.Get(id, keys, options...)
+	if err != nil {
+		return err
+	}
+
+	// Print results.
+	fmt.Table(os.Stdout, devices, keys)
+
+	return nil
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{mirrorSetCmd, mirrorOffCmd} {
+		cmd.Flags().StringVarP(&interfaceName, "interface", "i", "", "name of the interface to use")
+		cmd.MarkFlagRequired("interface")
+		cmd.Flags().StringVarP(&password, "password", "p", "", "password to use for authentication")
+		cmd.MarkFlagRequired("password")
+	}
+	mirrorSetCmd.Flags().Uint8Var(&mirrorTo, "to", 0, "destination port of the mirrored traffic")
+	mirrorSetCmd.MarkFlagRequired("to")
+	mirrorSetCmd.Flags().StringVar(&mirrorFrom, "from", "", "source ports of the mirrored traffic")
+	mirrorSetCmd.MarkFlagRequired("from")
+
+	mirrorCmd.AddCommand(mirrorSetCmd)
+	mirrorCmd.AddCommand(mirrorOffCmd)
+
+	rootCmd.AddCommand(mirrorCmd)
+}
@@ -72,11 +72,19 @@ You may specify the ports as a comma-separated list.`,
 			}
 		}
 
-		// Create slice to hold results.
+		// Create slices to hold results.
 		devices := make([]nsdp.Device, 0)
+		failures := make(nsdp.OperationErrors, 0)
+
+		// The first request only primes the ARP cache of the device and
+		// is often dropped, so always send the request at least twice.
+		attempts := retries + 1
+		if attempts < 2 {
+			attempts = 2
+		}
 
 		// Retry operation if retries is greater than 0.
-		for i := uint(0); i <= retries; i++ {
+		for i := uint(0); i < attempts; i++ {
 			// Create new message.
 			request := nsdp.NewMessage(nsdp.WriteRequest)
 
@@ -118,7 +126,12 @@ You may specify the ports as a comma-separated list.`,
 				nsdp.WithContext(ctx),
 				nsdp.WithSelector(selector),
 			)
-			if err != nil {
+
+			// Keep the devices that succeeded if some devices failed.
+			var opErrs nsdp.OperationErrors
+			if errors.As(err, &opErrs) {
+				failures = append(failures, opErrs...)
+			} else if err != nil {
 				return err
 			}
 
@@ -126,6 +139,11 @@ You may specify the ports as a comma-separated list.`,
 			devices = nsdp.DeduplicateDevices(devices, devs)
 		}
 
+		// Report the devices that failed.
+		if len(failures) > 0 {
+			return failures
+		}
+
 		// Check if any devices were found.
 		if len(devices) == 0 {
 			return errors.New("no switches found")
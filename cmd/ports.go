@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parsePorts parses a comma-separated list of ports,
+// which may also contain port ranges, such as "1,3-5".
+func parsePorts(list string) ([]uint8, error) {
+	ports := make([]uint8, 0)
+	if list == "" {
+		return ports, nil
+	}
+
+	for _, item := range strings.Split(list, ",") {
+		bounds := strings.SplitN(strings.TrimSpace(item), "-", 2)
+
+		first, err := strconv.ParseUint(bounds[0], 10, 8)
+		if err != nil {
+			return nil, fmt.Errorf(`invalid port "%s"`, item)
+		}
+
+		last := first
+		if len(bounds) == 2 {
+			last, err = strconv.ParseUint(bounds[1], 10, 8)
+			if err != nil || last < first {
+				return nil, fmt.Errorf(`invalid port range "%s"`, item)
+			}
+		}
+
+		for port := first; port <= last; port++ {
+			ports = append(ports, uint8(port))
+		}
+	}
+
+	return ports, nil
+}
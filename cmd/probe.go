@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/nicklasfrahm/netadm/pkg/nsdp"
+	"github.com/spf13/cobra"
+)
+
+var probeRange string
+var probeBatchSize int
+var probeOutput string
+
+var probeCmd = &cobra.Command{
+	Use:   "probe <device>",
+	Short: "Probe a device for supported records",
+	Long: `Probe a device for supported records by
+reading all record IDs in a range.
+
+The records that the device responds
+with are printed with their length and
+raw value. If you pass the "--output"
+flag, the results are also saved as a
+capability profile in JSON format, which
+you may share to extend the list of
+known configuration keys.
+
+By default the range from the lowest to
+the highest known record ID is probed.
+Action records, such as the reboot, are
+never probed. Probing a large range may
+take a while, as a batch that the device
+rejects is probed record by record.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id := args[0]
+		if id == "all" {
+			return errors.New("probing all devices is not supported")
+		}
+
+		from, to, err := parseRecordTypeRange(probeRange)
+		if err != nil {
+			return err
+		}
+
+		profile, err := nsdp.Probe(id, from, to, probeBatchSize, commonOptions()...)
+		if err != nil {
+			return err
+		}
+
+		if probeOutput != "" {
+			data, err := json.MarshalIndent(profile, "", "  ")
+			if err != nil {
+				return err
+			}
+
+			if err := os.WriteFile(probeOutput, append(data, '\n'), 0644); err != nil {
+				return err
+			}
+		}
+
+		return printProfile(os.Stdout, profile)
+	},
+}
+
+// parseRecordTypeRange parses a range of record type IDs, such as "0x0000-0xFFFF".
+func parseRecordTypeRange(s string) (nsdp.RecordTypeID, nsdp.RecordTypeID, error) {
+	var from, to nsdp.RecordTypeID
+
+	bounds := strings.SplitN(s, "-", 2)
+	if err := from.UnmarshalText([]byte(bounds[0])); err != nil {
+		return 0, 0, err
+	}
+
+	to = from
+	if len(bounds) == 2 {
+		if err := to.UnmarshalText([]byte(bounds[1])); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	if from > to {
+		return 0, 0, fmt.Errorf("invalid record type range: %s", s)
+	}
+
+	return from, to, nil
+}
+
+// printProfile prints the records of a capability profile as table.
+func printProfile(output io.Writer, profile *nsdp.Profile) error {
+	fmt.Fprintf(output, "MODEL: %s\nFIRMWARE: %s\n\n", profile.Model, profile.Firmware)
+
+	// Create table with tabwriter.
+	w := tabwriter.NewWriter(output, 0, 0, 4, ' ', tabwriter.TabIndent)
+	fmt.Fprintf(w, "ID\tNAME\tLEN\tVALUE\n")
+
+	for _, record := range profile.Records {
+		name := strings.ToLower(record.Name)
+		if name == "" {
+			name = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\n", record.ID, name, record.Len, record.Value)
+	}
+
+	return w.Flush()
+}
+
+func init() {
+	probeCmd.Flags().StringVarP(&interfaceName, "interface", "i", "", "name of the interface to use")
+	probeCmd.MarkFlagRequired("interface")
+	from, to := nsdp.KnownRecordRange()
+	probeCmd.Flags().StringVar(&probeRange, "range", fmt.Sprintf("%s-%s", from, to), "range of record IDs to probe")
+	probeCmd.Flags().IntVar(&probeBatchSize, "batch-size", nsdp.ProbeBatchSize, "number of record IDs per request")
+	probeCmd.Flags().StringVarP(&probeOutput, "output", "o", "", "file to save the capability profile to")
+
+	rootCmd.AddCommand(probeCmd)
+}
@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/nicklasfrahm/netadm/pkg/nsdp"
+	"github.com/spf13/cobra"
+)
+
+var qosCmd = &cobra.Command{
+	Use:   "qos",
+	Short: "Change quality of service settings",
+	Long: `Change the quality of service settings
+of a network device.
+
+You may specify the ports as a comma-
+separated list, which may also contain
+port ranges, such as "1,3-5".`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+		os.Exit(0)
+	},
+	SilenceUsage: true,
+}
+
+var qosListCmd = &cobra.Command{
+	Use:   "list <device>",
+	Short: "List the QoS priorities of all ports",
+	Long:  `List the QoS engine and the priorities of all ports of a device.`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return printQoS(os.Stdout, args[0], commonOptions()...)
+	},
+}
+
+var qosEngineCmd = &cobra.Command{
+	Use:   "engine <port|dscp> <device>",
+	Short: "Change the QoS engine",
+	Long: `Change the QoS engine of a device.
+
+With the "port" engine the traffic is
+prioritized based on the priority of
+the ingress port. With the "dscp" engine
+the traffic is prioritized based on the
+DSCP value of the IP header.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		engine, err := nsdp.ParseQoSEngine(args[0])
+		if err != nil {
+			return err
+		}
+
+		id := args[1]
+		if id == "all" {
+			return errors.New("writing to all devices is not supported")
+		}
+
+		opts := append(commonOptions(), nsdp.WithPassword(password))
+
+		if _, err := nsdp.SetQoSEngine(id, engine, opts...); err != nil {
+			return err
+		}
+
+		return printQoS(os.Stdout, id, opts...)
+	},
+}
+
+var qosSetCmd = &cobra.Command{
+	Use:   "set <device> <ports> <high|medium|normal|low>",
+	Short: "Change the QoS priority of ports",
+	Long: `Change the QoS priority of a single or
+multiple ports.
+
+The priorities only take effect with the
+port-based QoS engine, which you may
+enable via the "engine" subcommand.`,
+	Args: cobra.ExactArgs(3),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id := args[0]
+		if id == "all" {
+			return errors.New("writing to all devices is not supported")
+		}
+
+		ports, err := parsePorts(args[1])
+		if err != nil {
+			return err
+		}
+
+		priority, err := nsdp.ParseQoSPriority(args[2])
+		if err != nil {
+			return err
+		}
+
+		opts := append(commonOptions(), nsdp.WithPassword(password))
+
+		if _, err := nsdp.SetQoSPriority(id, ports, priority, opts...); err != nil {
+			return err
+		}
+
+		return printQoS(os.Stdout, id, opts...)
+	},
+}
+
+// printQoS reads the QoS engine and the priorities of all
+// ports of a device and prints them as table.
+func printQoS(output io.Writer, id string, options ...nsdp.Option) error {
+	devices, err := nsdp.Get(id, []string{"mac", "qosengine", "qospolicies"}, options...)
+	if err != nil {
+		return err
+	}
+
+	for i, device := range devices {
+		// Separate the tables of multiple devices.
+		if i > 0 {
+			fmt.Fprintln(output)
+		}
+
+		fmt.Fprintf(output, "DEVICE: %s\nENGINE: %s\n\n", device.MAC, device.QoSEngine)
+
+		// Create table with tabwriter.
+		w := tabwriter.NewWriter(output, 0, 0, 4, ' ', tabwriter.TabIndent)
+		fmt.Fprintf(w, "PORT\tPRIORITY\n")
+
+		for _, policy := range device.QoSPolicies {
+			fmt.Fprintf(w, "%d\t%s\n", policy.ID, policy.Priority)
+		}
+
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func init() {
+	qosListCmd.Flags().StringVarP(&interfaceName, "interface", "i", "", "name of the interface to use")
+	qosListCmd.MarkFlagRequired("interface")
+
+	for _, cmd := range []*cobra.Command{qosEngineCmd, qosSetCmd} {
+		cmd.Flags().StringVarP(&interfaceName, "interface", "i", "", "name of the interface to use")
+		cmd.MarkFlagRequired("interface")
+		cmd.Flags().StringVarP(&password, "password", "p", "", "password to use for authentication")
+		cmd.MarkFlagRequired("password")
+	}
+
+	qosCmd.AddCommand(qosListCmd)
+	qosCmd.AddCommand(qosEngineCmd)
+	qosCmd.AddCommand(qosSetCmd)
+
+	rootCmd.AddCommand(qosCmd)
+}
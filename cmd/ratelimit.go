@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/nicklasfrahm/netadm/pkg/nsdp"
+	"github.com/spf13/cobra"
+)
+
+var rateIn string
+var rateOut string
+
+var ratelimitCmd = &cobra.Command{
+	Use:   "ratelimit",
+	Short: "Change bandwidth limits",
+	Long: `Change the ingress and egress bandwidth
+limits of the ports of a network device.
+
+The rates may be specified in a human-
+readable format, such as "64Mbps" or
+"512k". As the devices only support a
+fixed set of limits, the rates will be
+snapped to the nearest supported limit.
+Use "none" to remove a limit.
+
+You may specify the ports as a comma-
+separated list, which may also contain
+port ranges, such as "1,3-5".`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+		os.Exit(0)
+	},
+	SilenceUsage: true,
+}
+
+var ratelimitListCmd = &cobra.Command{
+	Use:   "list <device>",
+	Short: "List the bandwidth limits of all ports",
+	Long:  `List the ingress and egress bandwidth limits of all ports of a device.`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return printRateLimits(os.Stdout, args[0], commonOptions()...)
+	},
+}
+
+var ratelimitSetCmd = &cobra.Command{
+	Use:   "set <device> <ports> [--in rate] [--out rate]",
+	Short: "Change the bandwidth limits of ports",
+	Long: `Change the ingress and egress bandwidth
+limits of a single or multiple ports.
+
+Directions that are not specified via
+the "--in" or "--out" flag will not be
+changed.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id := args[0]
+		if id == "all" {
+			return errors.New("writing to all devices is not supported")
+		}
+
+		ports, err := parsePorts(args[1])
+		if err != nil {
+			return err
+		}
+
+		if rateIn == "" && rateOut == "" {
+			return errors.New(`please specify a rate via "--in" or "--out"`)
+		}
+
+		var in, out *nsdp.BandwidthLimit
+		if rateIn != "" {
+			limit, err := parseRate(rateIn)
+			if err != nil {
+				return err
+			}
+			in = &limit
+		}
+		if rateOut != "" {
+			limit, err := parseRate(rateOut)
+			if err != nil {
+				return err
+			}
+			out = &limit
+		}
+
+		opts := append(commonOptions(), nsdp.WithPassword(password))
+
+		if _, err := nsdp.SetRateLimits(id, ports, in, out, opts...); err != nil {
+			return err
+		}
+
+		return printRateLimits(os.Stdout, id, opts...)
+	},
+}
+
+// parseRate parses a human-readable rate and informs
+// the user if it was snapped to a supported limit.
+func parseRate(rate string) (nsdp.BandwidthLimit, error) {
+	limit, err := nsdp.ParseBandwidthLimit(rate)
+	if err != nil {
+		return limit, err
+	}
+
+	if !strings.EqualFold(strings.ReplaceAll(rate, " ", ""), limit.String()) {
+		fmt.Fprintf(os.Stderr, "Rate \"%s\" applied as %s.\n", rate, limit)
+	}
+
+	return limit, nil
+}
+
+// printRateLimits reads the bandwidth limits of all
+// ports of a device and prints them as table.
+func printRateLimits(output io.Writer, id string, options ...nsdp.Option) error {
+	devices, err := nsdp.Get(id, []string{"mac", "bandwidthlimitsin", "bandwidthlimitsout"}, options...)
+	if err != nil {
+		return err
+	}
+
+	for i, device := range devices {
+		// Separate the tables of multiple devices.
+		if i > 0 {
+			fmt.Fprintln(output)
+		}
+
+		fmt.Fprintf(output, "DEVICE: %s\n\n", device.MAC)
+
+		// Create table with tabwriter.
+		w := tabwriter.NewWriter(output, 0, 0, 4, ' ', tabwriter.TabIndent)
+		fmt.Fprintf(w, "PORT\tIN\tOUT\n")
+
+		for j, in := range device.BandwidthLimitsIn {
+			out := "<nil>"
+			if j < len(device.BandwidthLimitsOut) {
+				out = device.BandwidthLimitsOut[j].Limit.String()
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\n", in.ID, in.Limit, out)
+		}
+
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func init() {
+	ratelimitListCmd.Flags().StringVarP(&interfaceName, "interface", "i", "", "name of the interface to use")
+	ratelimitListCmd.MarkFlagRequired("interface")
+
+	ratelimitSetCmd.Flags().StringVarP(&interfaceName, "interface", "i", "", "name of the interface to use")
+	ratelimitSetCmd.MarkFlagRequired("interface")
+	ratelimitSetCmd.Flags().StringVarP(&password, "password", "p", "", "password to use for authentication")
+	ratelimitSetCmd.MarkFlagRequired("password")
+	ratelimitSetCmd.Flags().StringVar(&rateIn, "in", "", "ingress bandwidth limit")
+	ratelimitSetCmd.Flags().StringVar(&rateOut, "out", "", "egress bandwidth limit")
+
+	ratelimitCmd.AddCommand(ratelimitListCmd)
+	ratelimitCmd.AddCommand(ratelimitSetCmd)
+
+	rootCmd.AddCommand(ratelimitCmd)
+}
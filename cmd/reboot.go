@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"time"
+
+	"github.com/nicklasfrahm/netadm/pkg/fmt"
+	"github.com/nicklasfrahm/netadm/pkg/nsdp"
+	"github.com/spf13/cobra"
+)
+
+var rebootCmd = &cobra.Command{
+	Use:   "reboot <device>",
+	Short: "Reboot a device",
+	Long: `A command that allows you to reboot a device.
+
+You will be asked to confirm the reboot
+unless you pass the "--yes" flag. If you
+pass the "--wait" flag, the command will
+only return once the device answers a
+scan again.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id := args[0]
+		if id == "all" {
+			return errors.New("rebooting all devices is not supported")
+		}
+
+		if err := confirm(`Reboot device "` + id + `"?`); err != nil {
+			return err
+		}
+
+		devices, err := nsdp.Reboot(id, append(commonOptions(), nsdp.WithPassword(password))...)
+		if err != nil {
+			return err
+		}
+
+		if !wait {
+			return nil
+		}
+
+		keys := []string{"name", "model", "mac", "ip"}
+		devices, err = waitForRestart(devices[0].MAC, keys)
+		if err != nil {
+			return err
+		}
+
+		// Print results.
+		fmt.Table(os.Stdout, devices, keys)
+
+		return nil
+	},
+}
+
+func init() {
+	rebootCmd.Flags().StringVarP(&interfaceName, "interface", "i", "", "name of the interface to use")
+	rebootCmd.MarkFlagRequired("interface")
+	rebootCmd.Flags().StringVarP(&password, "password", "p", "", "password to use for authentication")
+	rebootCmd.MarkFlagRequired("password")
+	rebootCmd.Flags().BoolVarP(&yes, "yes", "y", false, "skip the confirmation prompt")
+	rebootCmd.Flags().BoolVarP(&wait, "wait", "w", false, "wait until the device is back online")
+	rebootCmd.Flags().DurationVar(&waitTimeout, "wait-timeout", 2*time.Minute, "maximum time to wait for the device")
+
+	rootCmd.AddCommand(rebootCmd)
+}
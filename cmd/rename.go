@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+
+	"github.com/nicklasfrahm/netadm/pkg/fmt"
+	"github.com/nicklasfrahm/netadm/pkg/nsdp"
+	"github.com/spf13/cobra"
+)
+
+var renameCmd = &cobra.Command{
+	Use:   "rename <device> <name>",
+	Short: "Change the name of a device",
+	Long: `Change the name of a device.
+
+The name must not be longer than 20
+characters and may only contain
+printable ASCII characters. An empty
+name removes the name of the device.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id := args[0]
+		if id == "all" {
+			return errors.New("writing to all devices is not supported")
+		}
+
+		opts := append(commonOptions(), nsdp.WithPassword(password))
+
+		if _, err := nsdp.SetName(id, args[1], opts...); err != nil {
+			return err
+		}
+
+		keys := []string{"name", "model", "mac", "ip"}
+		devices, err := nsdp.Get(id, keys, opts...)
+		if err != nil {
+			return err
+		}
+
+		// Print results.
+		fmt.Table(os.Stdout, devices, keys)
+
+		return nil
+	},
+}
+
+func init() {
+	renameCmd.Flags().StringVarP(&interfaceName, "interface", "i", "", "name of the interface to use")
+	renameCmd.MarkFlagRequired("interface")
+	renameCmd.Flags().StringVarP(&password, "password", "p", "", "password to use for authentication")
+	renameCmd.MarkFlagRequired("password")
+
+	rootCmd.AddCommand(renameCmd)
+}
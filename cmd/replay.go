@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	nfmt "github.com/nicklasfrahm/netadm/pkg/fmt"
+	"github.com/nicklasfrahm/netadm/pkg/nsdp"
+	"github.com/nicklasfrahm/netadm/pkg/pcap"
+	"github.com/spf13/cobra"
+)
+
+var replayCmd = &cobra.Command{
+	Use:   "replay <file>",
+	Short: "Decode the messages of a capture",
+	Long: `Decode the messages of a pcap file offline.
+
+Every message is printed with its header
+and its records. The records of responses
+are additionally printed as table, just
+like the "get" command does.
+
+You may create a capture via the global
+"--capture" flag or with tools, such as
+Wireshark or tcpdump.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		file, err := os.Open(args[0])
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		reader, err := pcap.NewReader(file)
+		if err != nil {
+			return err
+		}
+
+		for i := 1; ; i++ {
+			packet, err := reader.ReadPacket()
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+
+			printPacket(os.Stdout, i, packet)
+		}
+	},
+}
+
+// printPacket prints a captured message and, if it is a
+// response, the device configuration contained in it.
+func printPacket(output io.Writer, i int, packet *pcap.Packet) {
+	// Skip packets that are not sent to or from the NSDP ports.
+	if packet.Src.Port != nsdp.ServerPort && packet.Dst.Port != nsdp.ServerPort {
+		return
+	}
+
+	fmt.Fprintf(output, "#%d %s %s -> %s\n", i, packet.Time.Format(time.RFC3339Nano), packet.Src, packet.Dst)
+
+	msg := new(nsdp.Message)
+	if err := msg.UnmarshalBinary(packet.Payload); err != nil {
+		fmt.Fprintf(output, "invalid message: %v\n\n", err)
+		return
+	}
+	nfmt.Message(output, msg)
+
+	if msg.Header.Operation == nsdp.ReadResponse {
+		device := nsdp.Device{}
+		if err := device.UnmarshalMessage(msg); err != nil {
+			fmt.Fprintln(output, err)
+		}
+
+		// Print all known configuration keys of the response.
+		keys := make([]string, 0, len(msg.Records))
+		seen := make(map[string]bool)
+		for _, record := range msg.Records {
+			rt := record.Type()
+			if rt != nil && rt.Example != nil && !seen[rt.Name] {
+				keys = append(keys, rt.Name)
+				seen[rt.Name] = true
+			}
+		}
+
+		if len(keys) > 0 {
+			fmt.Fprintln(output)
+			nfmt.Table(output, []nsdp.Device{device}, keys)
+		}
+	}
+
+	fmt.Fprintln(output)
+}
+
+func init() {
+	rootCmd.AddCommand(replayCmd)
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"time"
+
+	"github.com/nicklasfrahm/netadm/pkg/fmt"
+	"github.com/nicklasfrahm/netadm/pkg/nsdp"
+	"github.com/spf13/cobra"
+)
+
+var factory bool
+
+var resetCmd = &cobra.Command{
+	Use:   "reset --factory <device>",
+	Short: "Reset a device to its factory defaults",
+	Long: `A command that allows you to reset a
+device to its factory defaults.
+
+This will also reset the password and
+the IP configuration of the device. As
+only factory resets are supported, the
+"--factory" flag must be passed to make
+the intent explicit.
+
+You will be asked to confirm the reset
+unless you pass the "--yes" flag. If you
+pass the "--wait" flag, the command will
+only return once the device answers a
+scan again.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !factory {
+			return errors.New(`only factory resets are supported, please pass the "--factory" flag`)
+		}
+
+		id := args[0]
+		if id == "all" {
+			return errors.New("resetting all devices is not supported")
+		}
+
+		if err := confirm(`Reset device "` + id + `" to its factory defaults?`); err != nil {
+			return err
+		}
+
+		devices, err := nsdp.FactoryReset(id, append(commonOptions(), nsdp.WithPassword(password))...)
+		if err != nil {
+			return err
+		}
+
+		if !wait {
+			return nil
+		}
+
+		// The IP address of the device is likely to
+		// change, so we need to identify it via MAC.
+		keys := []string{"name", "model", "mac", "ip"}
+		devices, err = waitForRestart(devices[0].MAC, keys)
+		if err != nil {
+			return err
+		}
+
+		// Print results.
+		fmt.Table(os.Stdout, devices, keys)
+
+		return nil
+	},
+}
+
+func init() {
+	resetCmd.Flags().StringVarP(&interfaceName, "interface", "i", "", "name of the interface to use")
+	resetCmd.MarkFlagRequired("interface")
+	resetCmd.Flags().StringVarP(&password, "password", "p", "", "password to use for authentication")
+	resetCmd.MarkFlagRequired("password")
+	resetCmd.Flags().BoolVar(&factory, "factory", false, "reset the device to its factory defaults")
+	resetCmd.Flags().BoolVarP(&yes, "yes", "y", false, "skip the confirmation prompt")
+	resetCmd.Flags().BoolVarP(&wait, "wait", "w", false, "wait until the device is back online")
+	resetCmd.Flags().DurationVar(&waitTimeout, "wait-timeout", 2*time.Minute, "maximum time to wait for the device")
+
+	rootCmd.AddCommand(resetCmd)
+}
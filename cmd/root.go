@@ -1,17 +1,29 @@
 package cmd
 
 import (
+	"fmt"
+	"log/slog"
 	"os"
 	"time"
 
+	"github.com/nicklasfrahm/netadm/pkg/nsdp"
+	"github.com/nicklasfrahm/netadm/pkg/pcap"
 	"github.com/spf13/cobra"
 )
 
 var version = "dev"
 var interfaceName string
-var timeout time.Duration
+var timeout = 100 * time.Millisecond
+var autoTimeout bool
+var rttCachePath string
+var rttCache *nsdp.RTTCache
 var retries uint
+var retryPolicy = nsdp.DefaultRetryPolicy
+var noWarmup bool
 var help bool
+var captureFile string
+var verbosity int
+var capture *os.File
 
 var rootCmd = &cobra.Command{
 	Use:   "netadm",
@@ -20,15 +32,23 @@ var rootCmd = &cobra.Command{
 via different protocols.
 
 Note:
-  To achieve a consistent behavior all operations
-  are executed twice and the results are merged.
-  This is done to work around operations that do
-  not succeed if the device needs to refresh its
-  ARP cache by performing a MAC address lookup of
-  the host via the host IP. This happens on the
-  the first interaction or, I assume, when the
-  cache expires naturally, which appears to be
-  every 5 minutes or so.`,
+  A device does not respond to a request if it
+  first needs to refresh its ARP cache by looking
+  up the MAC address of the host via the host IP.
+  This happens on the first interaction or, I
+  assume, when the cache expires naturally, which
+  appears to be every 5 minutes or so. If there is
+  no response to the first request, a lightweight
+  read is sent to prime the ARP cache before the
+  operation is repeated. Writes are never repeated.
+  Pass "--no-warmup" to disable this behavior.
+
+Timeouts:
+  The round-trip times of all devices are kept in
+  the cache directory of the user. If the timeout
+  is set to "auto", the timeout of every attempt
+  is derived from them. Devices without measured
+  round-trip times use the default timeout.`,
 	PersistentPreRun: func(cmd *cobra.Command, args []string) {
 		if help {
 			cmd.Help()
@@ -45,13 +65,152 @@ Note:
 
 func init() {
 	rootCmd.PersistentFlags().BoolVarP(&help, "help", "h", false, "display help for command")
-	rootCmd.PersistentFlags().DurationVarP(&timeout, "timeout", "t", 100*time.Millisecond, "timeout per attempt")
-	rootCmd.PersistentFlags().UintVarP(&retries, "retries", "r", 1, "number of retries to perform")
+	rootCmd.PersistentFlags().VarP(&timeoutValue{}, "timeout", "t", `timeout per attempt or "auto" to derive it from measured round-trip times`)
+	rootCmd.PersistentFlags().UintVarP(&retries, "retries", "r", 0, "number of retries to perform")
+	rootCmd.PersistentFlags().DurationVar(&retryPolicy.Backoff, "backoff", nsdp.DefaultRetryPolicy.Backoff, "delay before the first retry, doubled for every further retry")
+	rootCmd.PersistentFlags().DurationVar(&retryPolicy.MaxBackoff, "max-backoff", nsdp.DefaultRetryPolicy.MaxBackoff, "maximum delay between two attempts")
+	rootCmd.PersistentFlags().DurationVar(&retryPolicy.QuietPeriod, "quiet-period", 0, "end a scan once no new device responded for this duration")
+	rootCmd.PersistentFlags().BoolVar(&noWarmup, "no-warmup", false, "do not prime the ARP cache of devices or repeat scans")
+	rootCmd.PersistentFlags().StringVar(&captureFile, "capture", "", "write all messages to a pcap file")
+	rootCmd.PersistentFlags().CountVarP(&verbosity, "verbose", "v", "increase log verbosity, pass twice to log raw messages")
+	// Define the version flag without a shorthand, as "-v" is used for the verbosity.
+	rootCmd.Flags().Bool("version", false, "version for netadm")
+
+	cobra.OnInitialize(setupLogger, startCapture, loadRTTCache)
+}
+
+// commonOptions returns the options that are configured via the
+// persistent flags and shared by the operations of all commands.
+func commonOptions() []nsdp.Option {
+	return []nsdp.Option{
+		nsdp.WithInterfaceName(interfaceName),
+		nsdp.WithRetries(retries),
+		nsdp.WithTimeout(timeout),
+		nsdp.WithAdaptiveTimeout(autoTimeout),
+		nsdp.WithRetryPolicy(&retryPolicy),
+		nsdp.WithWarmup(!noWarmup),
+	}
+}
+
+// timeoutValue is a flag value that accepts a duration or the keyword "auto".
+type timeoutValue struct{}
+
+// String returns the current value of the timeout.
+func (v *timeoutValue) String() string {
+	if autoTimeout {
+		return "auto"
+	}
+	return timeout.String()
+}
+
+// Set parses a duration or the keyword "auto". With "auto", the timeout
+// is derived from the round-trip times of the device and the default
+// timeout is only used for devices without measurements.
+func (v *timeoutValue) Set(value string) error {
+	if value == "auto" {
+		autoTimeout = true
+		return nil
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return err
+	}
+	timeout = duration
+	autoTimeout = false
+	return nil
+}
+
+// Type returns the type of the flag value shown in the help.
+func (v *timeoutValue) Type() string {
+	return "duration"
+}
+
+// setupLogger configures the default logger based on the verbosity.
+func setupLogger() {
+	level := slog.LevelWarn
+	switch {
+	case verbosity == 1:
+		level = slog.LevelDebug
+	case verbosity > 1:
+		level = nsdp.LevelTrace
+	}
+
+	handler := slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
+		Level: level,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.LevelKey && a.Value.Any() == nsdp.LevelTrace {
+				a.Value = slog.StringValue("TRACE")
+			}
+			return a
+		},
+	})
+	slog.SetDefault(slog.New(handler))
+}
+
+// startCapture creates the pcap file if the "--capture" flag is set.
+func startCapture() {
+	if captureFile == "" {
+		return
+	}
+
+	var err error
+	capture, err = os.Create(captureFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	writer, err := pcap.NewWriter(capture)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	nsdp.SetCapture(writer)
+}
+
+// loadRTTCache loads the round-trip times measured by previous invocations
+// from the cache directory of the user. Failures are not fatal, as the
+// cache is only needed to derive the timeout if "--timeout auto" is set.
+func loadRTTCache() {
+	var err error
+	rttCachePath, err = nsdp.DefaultRTTCachePath()
+	if err != nil {
+		slog.Debug("failed to locate round-trip time cache", "error", err)
+		return
+	}
+
+	rttCache, err = nsdp.LoadRTTCache(rttCachePath)
+	if err != nil {
+		slog.Warn("ignoring invalid round-trip time cache", "path", rttCachePath, "error", err)
+		rttCache = nsdp.NewRTTCache()
+	}
+
+	nsdp.SetRTTCache(rttCache)
+}
+
+// saveRTTCache persists the round-trip times measured by this invocation.
+func saveRTTCache() {
+	if rttCache == nil || !rttCache.Modified() {
+		return
+	}
+
+	if err := rttCache.Save(rttCachePath); err != nil {
+		slog.Debug("failed to save round-trip time cache", "path", rttCachePath, "error", err)
+	}
 }
 
 // Execute starts the invocation of the command line interface.
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	err := rootCmd.Execute()
+
+	if capture != nil {
+		capture.Close()
+	}
+	saveRTTCache()
+
+	if err != nil {
 		os.Exit(1)
 	}
 }
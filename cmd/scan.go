@@ -15,6 +15,9 @@ var scanCmd = &cobra.Command{
 
 The program will exit with a non-zero exit
 code if the scan does not return anything.
+If some devices report a failure, the other
+devices are printed and the failures are
+summarized before exiting with an error.
 
 If your scan doesn't return any devices
 despite them being present on your network
@@ -23,18 +26,25 @@ please increase the timeout and try again.`,
 		id := "all"
 		keys := []string{"name", "model", "mac", "ip", "dhcp", "firmware", "passwordencryption"}
 
-		devices, err := nsdp.Get(id, keys,
-			nsdp.WithInterfaceName(interfaceName),
-			nsdp.WithRetries(retries),
-			nsdp.WithTimeout(timeout),
-		)
+		results, err := nsdp.GetResults(id, keys, commonOptions()...)
 		if err != nil {
 			return err
 		}
 
-		// Print results.
+		// Print the results of all devices that succeeded.
+		devices := make([]nsdp.Device, 0, len(results))
+		for _, result := range results {
+			if result.Err == nil {
+				devices = append(devices, result.Device)
+			}
+		}
 		fmt.Table(os.Stdout, devices, keys)
 
+		// Summarize the devices that failed.
+		if fmt.Failures(os.Stderr, results) > 0 {
+			return nsdp.ErrDevicesFailed
+		}
+
 		return nil
 	},
 }
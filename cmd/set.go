@@ -20,12 +20,7 @@ You may run the "keys" subcommand
 to see a list of available keys.`,
 	Args: cobra.MinimumNArgs(2),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		opts := []nsdp.Option{
-			nsdp.WithInterfaceName(interfaceName),
-			nsdp.WithRetries(retries),
-			nsdp.WithTimeout(timeout),
-			nsdp.WithPassword(password),
-		}
+		opts := append(commonOptions(), nsdp.WithPassword(password))
 
 		id := args[0]
 		if id == "all" {
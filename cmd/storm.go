@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/nicklasfrahm/netadm/pkg/nsdp"
+	"github.com/spf13/cobra"
+)
+
+var stormCmd = &cobra.Command{
+	Use:   "storm",
+	Short: "Change broadcast storm control settings",
+	Long: `Change the broadcast storm control
+settings of a network device.
+
+The broadcast bandwidth limits of the
+ports only take effect if storm control
+is enabled via the "enable" subcommand.
+
+The rates may be specified in a human-
+readable format, such as "4Mbps" or
+"512k". As the devices only support a
+fixed set of limits, the rates will be
+snapped to the nearest supported limit.
+Use "none" to remove a limit.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+		os.Exit(0)
+	},
+	SilenceUsage: true,
+}
+
+var stormListCmd = &cobra.Command{
+	Use:   "list <device>",
+	Short: "List the broadcast bandwidth limits of all ports",
+	Long:  `List the storm control status and the broadcast bandwidth limits of all ports of a device.`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return printStormControl(os.Stdout, args[0], commonOptions()...)
+	},
+}
+
+var stormSetCmd = &cobra.Command{
+	Use:   "set <device> <ports> <rate>",
+	Short: "Change the broadcast bandwidth limit of ports",
+	Long: `Change the broadcast bandwidth limit of a
+single or multiple ports.
+
+You may specify the ports as a comma-
+separated list, which may also contain
+port ranges, such as "1,3-5".`,
+	Args: cobra.ExactArgs(3),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id := args[0]
+		if id == "all" {
+			return errors.New("writing to all devices is not supported")
+		}
+
+		ports, err := parsePorts(args[1])
+		if err != nil {
+			return err
+		}
+
+		limit, err := parseRate(args[2])
+		if err != nil {
+			return err
+		}
+
+		opts := append(commonOptions(), nsdp.WithPassword(password))
+
+		if _, err := nsdp.SetStormControl(id, ports, limit, opts...); err != nil {
+			return err
+		}
+
+		return printStormControl(os.Stdout, id, opts...)
+	},
+}
+
+var stormEnableCmd = &cobra.Command{
+	Use:   "enable <device>",
+	Short: "Enable broadcast storm control",
+	Long:  `Enable the broadcast storm control of a device.`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return setBroadcastFilter(args[0], true)
+	},
+}
+
+var stormDisableCmd = &cobra.Command{
+	Use:   "disable <device>",
+	Short: "Disable broadcast storm control",
+	Long:  `Disable the broadcast storm control of a device.`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return setBroadcastFilter(args[0], false)
+	},
+}
+
+// setBroadcastFilter enables or disables storm control and prints the result.
+func setBroadcastFilter(id string, enabled bool) error {
+	if id == "all" {
+		return errors.New("writing to all devices is not supported")
+	}
+
+	opts := append(commonOptions(), nsdp.WithPassword(password))
+
+	if _, err := nsdp.SetBroadcastFilter(id, enabled, opts...); err != nil {
+		return err
+	}
+
+	return printStormControl(os.Stdout, id, opts...)
+}
+
+// printStormControl reads the storm control status and the broadcast
+// bandwidth limits of all ports of a device and prints them as table.
+func printStormControl(output io.Writer, id string, options ...nsdp.Option) error {
+	devices, err := nsdp.Get(id, []string{"mac", "broadcastfilter", "broadcastlimits"}, options...)
+	if err != nil {
+		return err
+	}
+
+	for i, device := range devices {
+		// Separate the tables of multiple devices.
+		if i > 0 {
+			fmt.Fprintln(output)
+		}
+
+		status := "Disabled"
+		if device.BroadcastFilter {
+			status = "Enabled"
+		}
+		fmt.Fprintf(output, "DEVICE: %s\nSTORM CONTROL: %s\n\n", device.MAC, status)
+
+		// Create table with tabwriter.
+		w := tabwriter.NewWriter(output, 0, 0, 4, ' ', tabwriter.TabIndent)
+		fmt.Fprintf(w, "PORT\tLIMIT\n")
+
+		for _, policy := range device.BroadcastLimits {
+			fmt.Fprintf(w, "%d\t%s\n", policy.ID, policy.Limit)
+		}
+
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func init() {
+	stormListCmd.Flags().StringVarP(&interfaceName, "interface", "i", "", "name of the interface to use")
+	stormListCmd.MarkFlagRequired("interface")
+
+	for _, cmd := range []*cobra.Command{stormSetCmd, stormEnableCmd, stormDisableCmd} {
+		cmd.Flags().StringVarP(&interfaceName, "interface", "i", "", "name of the interface to use")
+		cmd.MarkFlagRequired("interface")
+		cmd.Flags().StringVarP(&password, "password", "p", "", "password to use for authentication")
+		cmd.MarkFlagRequired("password")
+	}
+
+	stormCmd.AddCommand(stormListCmd)
+	stormCmd.AddCommand(stormSetCmd)
+	stormCmd.AddCommand(stormEnableCmd)
+	stormCmd.AddCommand(stormDisableCmd)
+
+	rootCmd.AddCommand(stormCmd)
+}
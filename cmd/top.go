@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"text/tabwriter"
+	"time"
+
+	nfmt "github.com/nicklasfrahm/netadm/pkg/fmt"
+	"github.com/nicklasfrahm/netadm/pkg/nsdp"
+	"github.com/spf13/cobra"
+)
+
+var topInterval time.Duration
+var topCount uint
+
+var topCmd = &cobra.Command{
+	Use:   "top <device>",
+	Short: "Show the traffic of all ports",
+	Long: `Show the traffic of all ports of a device
+in a continuously refreshing table.
+
+The traffic is calculated by sampling
+the traffic counters of the ports at the
+configured interval. The utilization is
+the share of the link speed that is used
+by the received or sent traffic. The
+CRC errors are the errors that occurred
+since the previous sample, as the devices
+do not report any other error counters.
+
+The command runs until it is interrupted
+or until the number of refreshes passed
+via the "--count" flag is reached.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer cancel()
+
+		// Reject invalid device identifiers before polling, as
+		// errors during polling are not fatal.
+		if _, err := nsdp.ParseSelector(args[0]); err != nil {
+			return err
+		}
+
+		keys := []string{"name", "mac", "portspeeds", "portmetrics"}
+		opts := commonOptions()
+
+		var previous *nsdp.Device
+		var previousTime time.Time
+		refreshes := uint(0)
+
+		ticker := time.NewTicker(topInterval)
+		defer ticker.Stop()
+
+		for {
+			devices, err := nsdp.Get(args[0], keys, opts...)
+			if err != nil {
+				// A single missed poll must not end the command, so the
+				// previous sample is kept until the next poll succeeds.
+				slog.Warn("failed to poll device", "device", args[0], "error", err)
+			} else {
+				if len(devices) != 1 {
+					return nsdp.ErrMultipleDevices
+				}
+
+				now := time.Now()
+				if previous != nil {
+					printTop(os.Stdout, previous, &devices[0], now.Sub(previousTime))
+
+					refreshes++
+					if topCount > 0 && refreshes >= topCount {
+						return nil
+					}
+				}
+				previous = &devices[0]
+				previousTime = now
+			}
+
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-ticker.C:
+			}
+		}
+	},
+}
+
+// printTop clears the terminal and prints the traffic of all
+// ports based on the difference between the two samples.
+func printTop(output io.Writer, previous *nsdp.Device, current *nsdp.Device, elapsed time.Duration) {
+	// Move the cursor to the top left and clear the screen.
+	fmt.Fprint(output, "\033[H\033[2J")
+	fmt.Fprintf(output, "DEVICE: %s (%s)\n\n", current.Name, current.MAC)
+
+	// Index the previous samples by port.
+	samples := make(map[uint8]nsdp.PortMetric, len(previous.PortMetrics))
+	for _, metric := range previous.PortMetrics {
+		samples[metric.ID] = metric
+	}
+
+	speeds := make(map[uint8]nsdp.LinkStatus, len(current.PortSpeeds))
+	for _, speed := range current.PortSpeeds {
+		speeds[speed.ID] = speed.Speed
+	}
+
+	// Create table with tabwriter.
+	w := tabwriter.NewWriter(output, 0, 0, 4, ' ', tabwriter.TabIndent)
+	fmt.Fprintf(w, "PORT\tLINK\tRX\tTX\tRX%%\tTX%%\tCRC ERRORS\n")
+
+	for _, metric := range current.PortMetrics {
+		sample, ok := samples[metric.ID]
+		if !ok {
+			continue
+		}
+
+		delta := metric.Sub(sample)
+		rx := float64(delta.BytesReceived*8) / elapsed.Seconds()
+		tx := float64(delta.BytesSent*8) / elapsed.Seconds()
+
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%d\n",
+			metric.ID,
+			speeds[metric.ID],
+			nfmt.Rate(rx),
+			nfmt.Rate(tx),
+			utilization(rx, speeds[metric.ID]),
+			utilization(tx, speeds[metric.ID]),
+			delta.ErrorsPacketCRC,
+		)
+	}
+
+	w.Flush()
+}
+
+// utilization calculates the share of the link speed used by the rate.
+func utilization(rate float64, speed nsdp.LinkStatus) string {
+	if speed.Rate() == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", 100*rate/float64(speed.Rate()))
+}
+
+func init() {
+	topCmd.Flags().StringVarP(&interfaceName, "interface", "i", "", "name of the interface to use")
+	topCmd.MarkFlagRequired("interface")
+	topCmd.Flags().DurationVar(&topInterval, "interval", 2*time.Second, "interval between two refreshes")
+	topCmd.Flags().UintVarP(&topCount, "count", "n", 0, "number of refreshes before exiting")
+
+	rootCmd.AddCommand(topCmd)
+}
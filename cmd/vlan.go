@@ -0,0 +1,354 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/nicklasfrahm/netadm/pkg/nsdp"
+	"github.com/spf13/cobra"
+)
+
+var vlanTagged string
+var vlanUntagged string
+var vlanRemove string
+var vlanPVID bool
+var hostPort uint8
+
+var vlanCmd = &cobra.Command{
+	Use:   "vlan",
+	Short: "Manage VLANs",
+	Long: `Manage the VLANs of a network device.
+
+The subcommands on this level manage
+802.1Q VLANs. The tagged and untagged
+membership of the ports is managed
+together with the PVIDs of the ports.
+Changes that would make a port
+unreachable are rejected. Changes of
+PVIDs or untagged members require the
+"--host-port" flag, which is the port
+of the management host. It must stay
+an untagged member of its PVID VLAN.
+Port-based VLANs are managed via the
+"port" subcommand.
+
+You may specify the ports as a comma-
+separated list, which may also contain
+port ranges, such as "1,3-5".`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+		os.Exit(0)
+	},
+	SilenceUsage: true,
+}
+
+var vlanListCmd = &cobra.Command{
+	Use:   "list <device>",
+	Short: "List 802.1Q VLANs",
+	Long: `List the 802.1Q VLANs of a device, their
+tagged and untagged member ports, and the
+ports that use the VLAN as their PVID.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		table, err := nsdp.GetVLAN802QTable(args[0], commonOptions()...)
+		if err != nil {
+			return err
+		}
+
+		return printVLAN802QTable(os.Stdout, table)
+	},
+}
+
+var vlanAddCmd = &cobra.Command{
+	Use:   "add <device> <vlan>",
+	Short: "Create an 802.1Q VLAN",
+	Long: `Create an 802.1Q VLAN with the specified
+tagged and untagged member ports.
+
+If you pass the "--pvid" flag, the VLAN
+will also become the PVID of all of its
+untagged member ports.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return updateVLAN802QTable(args[0], func(table *nsdp.VLAN802QTable) error {
+			id, tagged, untagged, err := parseVLANMembers(args[1])
+			if err != nil {
+				return err
+			}
+
+			if err := table.Add(id, tagged, untagged); err != nil {
+				return err
+			}
+
+			if vlanPVID {
+				return table.SetPVID(id, untagged)
+			}
+
+			return nil
+		})
+	},
+}
+
+var vlanDeleteCmd = &cobra.Command{
+	Use:   "delete <device> <vlan>",
+	Short: "Delete an 802.1Q VLAN",
+	Long: `Delete an 802.1Q VLAN.
+
+A VLAN can't be deleted while it is the
+PVID of any port. Please assign another
+PVID to these ports first.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return updateVLAN802QTable(args[0], func(table *nsdp.VLAN802QTable) error {
+			id, err := parseVLANID(args[1])
+			if err != nil {
+				return err
+			}
+
+			return table.Delete(id)
+		})
+	},
+}
+
+var vlanMembersCmd = &cobra.Command{
+	Use:   "members <device> <vlan>",
+	Short: "Change the member ports of an 802.1Q VLAN",
+	Long: `Change the tagged and untagged member
+ports of an 802.1Q VLAN.
+
+Ports passed via "--tagged" or "--untagged"
+are added to the VLAN or moved to the given
+group if they already are members. Ports
+passed via "--remove" are removed from the
+VLAN. If you pass the "--pvid" flag, the
+VLAN will also become the PVID of the
+specified untagged ports.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return updateVLAN802QTable(args[0], func(table *nsdp.VLAN802QTable) error {
+			id, tagged, untagged, err := parseVLANMembers(args[1])
+			if err != nil {
+				return err
+			}
+
+			removed, err := parsePorts(vlanRemove)
+			if err != nil {
+				return err
+			}
+
+			if err := table.SetMembers(id, tagged, untagged); err != nil {
+				return err
+			}
+
+			if vlanPVID {
+				if err := table.SetPVID(id, untagged); err != nil {
+					return err
+				}
+			}
+
+			return table.RemoveMembers(id, removed)
+		})
+	},
+}
+
+var vlanEngineCmd = &cobra.Command{
+	Use:   "engine <device> <engine>",
+	Short: "Change the VLAN engine",
+	Long: `Change the VLAN engine of a device.
+
+The following engines are available:
+  - disabled
+  - port-basic
+  - port-advanced
+  - 802.1q-basic
+  - 802.1q-advanced
+
+WARNING:
+  Changing the VLAN engine deletes all
+  existing VLANs and resets the PVIDs of
+  all ports. You will be asked to confirm
+  the change unless you pass the "--yes"
+  flag.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id := args[0]
+		if id == "all" {
+			return errors.New("writing to all devices is not supported")
+		}
+
+		engine, err := nsdp.ParseVLANEngine(args[1])
+		if err != nil {
+			return err
+		}
+
+		opts := append(commonOptions(), nsdp.WithPassword(password))
+
+		devices, err := nsdp.Get(id, []string{"vlanengine"}, opts...)
+		if err != nil {
+			return err
+		}
+
+		if devices[0].VLANEngine == engine {
+			fmt.Printf("VLAN engine is already %s\n", engine)
+			return nil
+		}
+
+		fmt.Fprintf(os.Stderr, "WARNING: Changing the VLAN engine from %s to %s deletes all existing VLANs.\n", devices[0].VLANEngine, engine)
+		if err := confirm(`Change the VLAN engine of device "` + id + `"?`); err != nil {
+			return err
+		}
+
+		if _, err := nsdp.SetVLANEngine(id, engine, opts...); err != nil {
+			return err
+		}
+
+		fmt.Printf("VLAN engine changed to %s\n", engine)
+
+		return nil
+	},
+}
+
+// updateVLAN802QTable reads the VLAN table of a device, applies the
+// changes and writes them back. Afterwards it prints the new table.
+func updateVLAN802QTable(id string, update func(table *nsdp.VLAN802QTable) error) error {
+	if id == "all" {
+		return errors.New("writing to all devices is not supported")
+	}
+
+	opts := append(commonOptions(), nsdp.WithPassword(password))
+
+	table, err := nsdp.GetVLAN802QTable(id, opts...)
+	if err != nil {
+		return err
+	}
+	table.HostPort = hostPort
+
+	if err := update(table); err != nil {
+		return err
+	}
+
+	if _, err := nsdp.SetVLAN802QTable(id, table, opts...); err != nil {
+		return hostPortHint(err)
+	}
+
+	// Read the table again to show the applied configuration.
+	table, err = nsdp.GetVLAN802QTable(id, opts...)
+	if err != nil {
+		return err
+	}
+
+	return printVLAN802QTable(os.Stdout, table)
+}
+
+// hostPortHint explains how to pass the port of the management host
+// if a change was rejected because it is unknown.
+func hostPortHint(err error) error {
+	if errors.Is(err, nsdp.ErrHostPortRequired) {
+		return fmt.Errorf(`%w, please pass it via "--host-port"`, err)
+	}
+	return err
+}
+
+// printVLAN802QTable prints the VLANs and the PVIDs of the ports.
+func printVLAN802QTable(output io.Writer, table *nsdp.VLAN802QTable) error {
+	fmt.Fprintf(output, "ENGINE: %s\n\n", table.Engine)
+
+	// Create table with tabwriter.
+	w := tabwriter.NewWriter(output, 0, 0, 4, ' ', tabwriter.TabIndent)
+	fmt.Fprintf(w, "VLAN\tTAGGED\tUNTAGGED\tPVID\n")
+
+	pvids := table.PVIDs()
+	for _, vlan := range table.VLANs() {
+		ports := make([]string, 0)
+		for _, pvid := range pvids {
+			if pvid.PVID == vlan.ID {
+				ports = append(ports, strconv.Itoa(int(pvid.ID)))
+			}
+		}
+
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", vlan.ID, joinPorts(vlan.Tagged), joinPorts(vlan.Untagged), strings.Join(ports, ","))
+	}
+
+	return w.Flush()
+}
+
+// parseVLANMembers parses the VLAN ID and the member ports from the flags.
+func parseVLANMembers(vlan string) (uint16, []uint8, []uint8, error) {
+	id, err := parseVLANID(vlan)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+
+	tagged, err := parsePorts(vlanTagged)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+
+	untagged, err := parsePorts(vlanUntagged)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+
+	return id, tagged, untagged, nil
+}
+
+// parseVLANID parses a VLAN ID.
+func parseVLANID(vlan string) (uint16, error) {
+	id, err := strconv.ParseUint(vlan, 10, 16)
+	if err != nil {
+		return 0, fmt.Errorf(`invalid VLAN ID "%s"`, vlan)
+	}
+	return uint16(id), nil
+}
+
+// joinPorts converts a slice of ports to a comma-separated list.
+func joinPorts(ports []uint8) string {
+	if len(ports) == 0 {
+		return "-"
+	}
+
+	items := make([]string, len(ports))
+	for i, port := range ports {
+		items[i] = strconv.Itoa(int(port))
+	}
+	return strings.Join(items, ",")
+}
+
+func init() {
+	vlanListCmd.Flags().StringVarP(&interfaceName, "interface", "i", "", "name of the interface to use")
+	vlanListCmd.MarkFlagRequired("interface")
+
+	for _, cmd := range []*cobra.Command{vlanAddCmd, vlanDeleteCmd, vlanMembersCmd} {
+		cmd.Flags().StringVarP(&interfaceName, "interface", "i", "", "name of the interface to use")
+		cmd.MarkFlagRequired("interface")
+		cmd.Flags().StringVarP(&password, "password", "p", "", "password to use for authentication")
+		cmd.MarkFlagRequired("password")
+		cmd.Flags().Uint8Var(&hostPort, "host-port", 0, "port of the management host, required to change PVIDs or untagged members")
+	}
+
+	for _, cmd := range []*cobra.Command{vlanAddCmd, vlanMembersCmd} {
+		cmd.Flags().StringVar(&vlanTagged, "tagged", "", "ports to add as tagged members")
+		cmd.Flags().StringVar(&vlanUntagged, "untagged", "", "ports to add as untagged members")
+		cmd.Flags().BoolVar(&vlanPVID, "pvid", false, "use the VLAN as PVID of the untagged ports")
+	}
+	vlanMembersCmd.Flags().StringVar(&vlanRemove, "remove", "", "ports to remove from the VLAN")
+
+	vlanEngineCmd.Flags().StringVarP(&interfaceName, "interface", "i", "", "name of the interface to use")
+	vlanEngineCmd.MarkFlagRequired("interface")
+	vlanEngineCmd.Flags().StringVarP(&password, "password", "p", "", "password to use for authentication")
+	vlanEngineCmd.MarkFlagRequired("password")
+	vlanEngineCmd.Flags().BoolVarP(&yes, "yes", "y", false, "skip the confirmation prompt")
+
+	vlanCmd.AddCommand(vlanListCmd)
+	vlanCmd.AddCommand(vlanAddCmd)
+	vlanCmd.AddCommand(vlanDeleteCmd)
+	vlanCmd.AddCommand(vlanMembersCmd)
+	vlanCmd.AddCommand(vlanEngineCmd)
+
+	rootCmd.AddCommand(vlanCmd)
+}
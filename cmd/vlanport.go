@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/nicklasfrahm/netadm/pkg/nsdp"
+	"github.com/spf13/cobra"
+)
+
+var vlanAdd string
+
+var vlanPortCmd = &cobra.Command{
+	Use:   "port",
+	Short: "Manage port-based VLANs",
+	Long: `Manage the port-based VLANs of a network device.
+
+With the basic port-based VLAN engine
+a port can only be a member of a single
+VLAN. Changes that would leave a port
+without any VLAN are rejected. Removing
+members requires the "--host-port" flag,
+which is the port of the management
+host. It must stay a member of all of
+its VLANs.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+		os.Exit(0)
+	},
+}
+
+var vlanPortListCmd = &cobra.Command{
+	Use:   "list <device>",
+	Short: "List port-based VLANs",
+	Long:  `List the port-based VLANs of a device and their member ports.`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		table, err := nsdp.GetVLANPortTable(args[0], commonOptions()...)
+		if err != nil {
+			return err
+		}
+
+		return printVLANPortTable(os.Stdout, table)
+	},
+}
+
+var vlanPortAddCmd = &cobra.Command{
+	Use:   "add <device> <vlan> <ports>",
+	Short: "Create a port-based VLAN",
+	Long:  `Create a port-based VLAN with the specified member ports.`,
+	Args:  cobra.ExactArgs(3),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return updateVLANPortTable(args[0], func(table *nsdp.VLANPortTable) error {
+			id, err := parseVLANID(args[1])
+			if err != nil {
+				return err
+			}
+
+			ports, err := parsePorts(args[2])
+			if err != nil {
+				return err
+			}
+
+			return table.Add(id, ports)
+		})
+	},
+}
+
+var vlanPortDeleteCmd = &cobra.Command{
+	Use:   "delete <device> <vlan>",
+	Short: "Delete a port-based VLAN",
+	Long: `Delete a port-based VLAN.
+
+A VLAN can't be deleted while it is the
+only VLAN of any of its member ports.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return updateVLANPortTable(args[0], func(table *nsdp.VLANPortTable) error {
+			id, err := parseVLANID(args[1])
+			if err != nil {
+				return err
+			}
+
+			return table.Delete(id)
+		})
+	},
+}
+
+var vlanPortMembersCmd = &cobra.Command{
+	Use:   "members <device> <vlan>",
+	Short: "Change the member ports of a port-based VLAN",
+	Long: `Change the member ports of a port-based VLAN.
+
+Ports passed via "--add" are added to the
+VLAN and ports passed via "--remove" are
+removed from the VLAN.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return updateVLANPortTable(args[0], func(table *nsdp.VLANPortTable) error {
+			id, err := parseVLANID(args[1])
+			if err != nil {
+				return err
+			}
+
+			added, err := parsePorts(vlanAdd)
+			if err != nil {
+				return err
+			}
+
+			removed, err := parsePorts(vlanRemove)
+			if err != nil {
+				return err
+			}
+
+			if err := table.AddMembers(id, added); err != nil {
+				return err
+			}
+
+			return table.RemoveMembers(id, removed)
+		})
+	},
+}
+
+// updateVLANPortTable reads the VLAN table of a device, applies the
+// changes and writes them back. Afterwards it prints the new table.
+func updateVLANPortTable(id string, update func(table *nsdp.VLANPortTable) error) error {
+	if id == "all" {
+		return errors.New("writing to all devices is not supported")
+	}
+
+	opts := append(commonOptions(), nsdp.WithPassword(password))
+
+	table, err := nsdp.GetVLANPortTable(id, opts...)
+	if err != nil {
+		return err
+	}
+
+	table.HostPort = hostPort
+
+	if err := update(table); err != nil {
+		return err
+	}
+
+	if _, err := nsdp.SetVLANPortTable(id, table, opts...); err != nil {
+		return hostPortHint(err)
+	}
+
+	// Read the table again to show the applied configuration.
+	table, err = nsdp.GetVLANPortTable(id, opts...)
+	if err != nil {
+		return err
+	}
+
+	return printVLANPortTable(os.Stdout, table)
+}
+
+// printVLANPortTable prints the port-based VLANs and their member ports.
+func printVLANPortTable(output io.Writer, table *nsdp.VLANPortTable) error {
+	fmt.Fprintf(output, "ENGINE: %s\n\n", table.Engine)
+
+	// Create table with tabwriter.
+	w := tabwriter.NewWriter(output, 0, 0, 4, ' ', tabwriter.TabIndent)
+	fmt.Fprintf(w, "VLAN\tPORTS\n")
+
+	for _, vlan := range table.VLANs() {
+		fmt.Fprintf(w, "%d\t%s\n", vlan.ID, joinPorts(vlan.Ports))
+	}
+
+	return w.Flush()
+}
+
+func init() {
+	vlanPortListCmd.Flags().StringVarP(&interfaceName, "interface", "i", "", "name of the interface to use")
+	vlanPortListCmd.MarkFlagRequired("interface")
+
+	for _, cmd := range []*cobra.Command{vlanPortAddCmd, vlanPortDeleteCmd, vlanPortMembersCmd} {
+		cmd.Flags().StringVarP(&interfaceName, "interface", "i", "", "name of the interface to use")
+		cmd.MarkFlagRequired("interface")
+		cmd.Flags().StringVarP(&password, "password", "p", "", "password to use for authentication")
+		cmd.MarkFlagRequired("password")
+		cmd.Flags().Uint8Var(&hostPort, "host-port", 0, "port of the management host, required to remove members")
+	}
+	vlanPortMembersCmd.Flags().StringVar(&vlanAdd, "add", "", "ports to add to the VLAN")
+	vlanPortMembersCmd.Flags().StringVar(&vlanRemove, "remove", "", "ports to remove from the VLAN")
+
+	vlanPortCmd.AddCommand(vlanPortListCmd)
+	vlanPortCmd.AddCommand(vlanPortAddCmd)
+	vlanPortCmd.AddCommand(vlanPortDeleteCmd)
+	vlanPortCmd.AddCommand(vlanPortMembersCmd)
+
+	vlanCmd.AddCommand(vlanPortCmd)
+}
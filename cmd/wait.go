@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/nicklasfrahm/netadm/pkg/nsdp"
+)
+
+var wait bool
+var waitTimeout time.Duration
+
+// pollInterval is the time between two attempts to reach a device.
+const pollInterval = 500 * time.Millisecond
+
+// ErrWaitTimeout is returned if a device did not come back in time.
+var ErrWaitTimeout = errors.New("timed out waiting for device")
+
+// waitForRestart waits until a device first stops responding
+// and then answers a scan again, which indicates that it has
+// completed its restart. The device is identified via its MAC
+// as its IP address may change during the restart.
+func waitForRestart(mac net.HardwareAddr, keys []string) ([]nsdp.Device, error) {
+	deadline := time.Now().Add(waitTimeout)
+	offline := false
+
+	var lastErr error
+
+	for time.Now().Before(deadline) {
+		devices, err := nsdp.Get(mac.String(), keys, commonOptions()...)
+
+		// A device that is still starting may report failures, so
+		// they are only returned if the device does not recover.
+		var opErrs nsdp.OperationErrors
+		if errors.As(err, &opErrs) {
+			lastErr = err
+			time.Sleep(pollInterval)
+			continue
+		}
+		if err != nil && !errors.Is(err, nsdp.ErrNoDevicesFound) {
+			return nil, err
+		}
+
+		if err != nil {
+			offline = true
+		} else if offline {
+			return devices, nil
+		}
+
+		time.Sleep(pollInterval)
+	}
+
+	if lastErr != nil {
+		return nil, fmt.Errorf("%w: %w", ErrWaitTimeout, lastErr)
+	}
+	return nil, ErrWaitTimeout
+}
@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/nicklasfrahm/netadm/pkg/event"
+	"github.com/nicklasfrahm/netadm/pkg/nsdp"
+	"github.com/spf13/cobra"
+)
+
+var watchInterval time.Duration
+var webhook string
+var execCommand string
+var syslog bool
+var syslogAddress string
+
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Watch devices and emit events",
+	Long: `Watch devices by polling them at a fixed
+interval and emit events if a change is
+detected.
+
+The events are always printed as JSON to
+stdout. Additionally, they may be posted
+as JSON to a webhook via the "--webhook"
+flag, passed to a command via the "--exec"
+flag or written to syslog via the "--syslog"
+flag. Please note that syslog is not
+supported on Windows.
+
+The command passed via "--exec" is run
+by the system shell for every event. It
+receives the event as JSON via stdin and
+the fields of the event as environment
+variables, such as NETADM_EVENT_MAC.
+
+The command runs until it is interrupted.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+		os.Exit(0)
+	},
+	SilenceUsage: true,
+}
+
+// newSink creates the sink that events are emitted to based on the flags.
+func newSink() (event.Sink, error) {
+	sinks := event.MultiSink{event.NewJSONSink(os.Stdout)}
+
+	if webhook != "" {
+		sinks = append(sinks, event.NewWebhookSink(webhook))
+	}
+
+	if execCommand != "" {
+		sinks = append(sinks, event.NewExecSink(execCommand))
+	}
+
+	if syslog {
+		sink, err := event.NewSyslogSink(syslogAddress)
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, sink)
+	}
+
+	return sinks, nil
+}
+
+// emit emits an event to the sink. As the watch commands are long-running,
+// errors are only reported instead of terminating the command.
+func emit(sink event.Sink, e event.Event) {
+	if err := sink.Emit(e); err != nil {
+		slog.Warn("failed to emit event", "error", err)
+	}
+}
+
+// newEvent creates an event for the port of a device.
+func newEvent(t event.Type, device *nsdp.Device, port uint8, message string) event.Event {
+	return event.Event{
+		Time:    time.Now(),
+		Type:    t,
+		Device:  device.Name,
+		MAC:     device.MAC.String(),
+		IP:      device.IP.String(),
+		Port:    port,
+		Message: message,
+	}
+}
+
+// poll reads the configuration keys of the devices at every interval
+// and passes the devices to the handler until it is interrupted.
+func poll(id string, keys []string, handle func(devices []nsdp.Device)) error {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	ticker := time.NewTicker(watchInterval)
+	defer ticker.Stop()
+
+	for {
+		results, err := nsdp.GetResults(id, keys, commonOptions()...)
+		if err != nil {
+			// Devices may be temporarily unreachable,
+			// so we keep polling until interrupted.
+			slog.Warn("failed to poll devices", "device", id, "error", err)
+		} else {
+			// Keep watching the other devices if some devices fail.
+			devices := make([]nsdp.Device, 0, len(results))
+			for _, result := range results {
+				if result.Err != nil {
+					slog.Warn("failed to poll device", "device", result.Device.MAC.String(), "error", result.Err)
+					continue
+				}
+				devices = append(devices, result.Device)
+			}
+			handle(devices)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func init() {
+	watchCmd.PersistentFlags().StringVarP(&interfaceName, "interface", "i", "", "name of the interface to use")
+	watchCmd.MarkPersistentFlagRequired("interface")
+	watchCmd.PersistentFlags().DurationVar(&watchInterval, "interval", 5*time.Second, "interval between two polls")
+	watchCmd.PersistentFlags().StringVar(&webhook, "webhook", "", "URL to post events to")
+	watchCmd.PersistentFlags().StringVar(&execCommand, "exec", "", "command to run for every event")
+	watchCmd.PersistentFlags().BoolVar(&syslog, "syslog", false, "write events to syslog")
+	watchCmd.PersistentFlags().StringVar(&syslogAddress, "syslog-address", "", "address of a remote syslog server")
+
+	rootCmd.AddCommand(watchCmd)
+}
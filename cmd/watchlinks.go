@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/nicklasfrahm/netadm/pkg/event"
+	"github.com/nicklasfrahm/netadm/pkg/nsdp"
+	"github.com/spf13/cobra"
+)
+
+var watchLinksCmd = &cobra.Command{
+	Use:   "links <device|all>",
+	Short: "Watch devices for link status changes",
+	Long: `Watch devices for link status changes.
+
+A "link" event is emitted whenever the
+link status or the speed of a port
+changes, for example if an uplink is
+lost. The first poll only records the
+current link status of all ports.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		sink, err := newSink()
+		if err != nil {
+			return err
+		}
+
+		watcher := newLinkWatcher(sink)
+		keys := []string{"name", "mac", "ip", "portspeeds"}
+
+		return poll(args[0], keys, watcher.update)
+	},
+}
+
+// linkWatcher detects changes of the link status of all ports.
+type linkWatcher struct {
+	sink  event.Sink
+	links map[string]map[uint8]nsdp.LinkStatus
+}
+
+// newLinkWatcher creates a link watcher that emits events to the sink.
+func newLinkWatcher(sink event.Sink) *linkWatcher {
+	return &linkWatcher{
+		sink:  sink,
+		links: make(map[string]map[uint8]nsdp.LinkStatus),
+	}
+}
+
+// update processes a new sample of the devices.
+func (w *linkWatcher) update(devices []nsdp.Device) {
+	for i := range devices {
+		device := &devices[i]
+		mac := device.MAC.String()
+
+		links, known := w.links[mac]
+		if !known {
+			links = make(map[uint8]nsdp.LinkStatus)
+			w.links[mac] = links
+		}
+
+		for _, port := range device.PortSpeeds {
+			previous, ok := links[port.ID]
+			links[port.ID] = port.Speed
+
+			// Only emit events for changes after the first poll.
+			if !known || !ok || previous == port.Speed {
+				continue
+			}
+
+			e := newEvent(event.TypeLink, device, port.ID, fmt.Sprintf("port %d: %s -> %s", port.ID, previous, port.Speed))
+			e.From = previous.String()
+			e.To = port.Speed.String()
+			emit(w.sink, e)
+		}
+	}
+}
+
+func init() {
+	watchCmd.AddCommand(watchLinksCmd)
+}
@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/nicklasfrahm/netadm/pkg/event"
+	"github.com/nicklasfrahm/netadm/pkg/nsdp"
+	"github.com/spf13/cobra"
+)
+
+var loopEnable bool
+var loopThreshold uint64
+var loopSamples uint
+
+var watchLoopsCmd = &cobra.Command{
+	Use:   "loops <device|all>",
+	Short: "Watch devices for network loops",
+	Long: `Watch devices for network loops.
+
+A loop causes a broadcast storm, which
+is detected by monitoring the rate of
+broadcast packets received on each port.
+If the rate exceeds the threshold for
+the configured number of consecutive
+samples, a "loop" event is emitted for
+the port. Once the rate drops below the
+threshold, a "loop-cleared" event is
+emitted.
+
+If you pass the "--enable" flag, the
+loop detection of the devices will be
+enabled before watching them, which
+requires the "--password" flag.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id := args[0]
+
+		if loopSamples == 0 {
+			return errors.New(`flag "--samples" must be at least 1`)
+		}
+		if loopThreshold == 0 {
+			return errors.New(`flag "--threshold" must be at least 1`)
+		}
+
+		sink, err := newSink()
+		if err != nil {
+			return err
+		}
+
+		if loopEnable {
+			if password == "" {
+				return errors.New(`enabling loop detection requires the "--password" flag`)
+			}
+
+			if err := enableLoopDetection(id); err != nil {
+				return err
+			}
+		}
+
+		detector := newLoopDetector(sink)
+		keys := []string{"name", "mac", "ip", "loopdetection", "portmetrics"}
+
+		return poll(id, keys, detector.update)
+	},
+}
+
+// enableLoopDetection enables the loop detection on all selected devices.
+func enableLoopDetection(id string) error {
+	opts := append(commonOptions(), nsdp.WithPassword(password))
+
+	devices, err := nsdp.Get(id, []string{"mac", "loopdetection"}, opts...)
+	if err != nil {
+		return err
+	}
+
+	// Writes are only supported for a single device,
+	// so we need to enable it on each device separately.
+	for _, device := range devices {
+		if device.LoopDetection {
+			continue
+		}
+
+		if _, err := nsdp.SetLoopDetection(device.MAC.String(), true, opts...); err != nil {
+			return fmt.Errorf("failed to enable loop detection on %s: %w", device.MAC, err)
+		}
+	}
+
+	return nil
+}
+
+// loopPortState tracks the broadcast rate of a port across samples.
+type loopPortState struct {
+	metric  nsdp.PortMetric
+	time    time.Time
+	hits    uint
+	looping bool
+}
+
+// loopDetector detects loops based on the broadcast rate of all ports.
+type loopDetector struct {
+	sink   event.Sink
+	ports  map[string]map[uint8]*loopPortState
+	warned map[string]bool
+}
+
+// newLoopDetector creates a loop detector that emits events to the sink.
+func newLoopDetector(sink event.Sink) *loopDetector {
+	return &loopDetector{
+		sink:  sink,
+		ports: make(map[string]map[uint8]*loopPortState),
+		// Only warn once per device if loop detection is disabled.
+		warned: make(map[string]bool),
+	}
+}
+
+// update processes a new sample of the devices.
+func (d *loopDetector) update(devices []nsdp.Device) {
+	now := time.Now()
+
+	for i := range devices {
+		device := &devices[i]
+		mac := device.MAC.String()
+
+		if !device.LoopDetection && !d.warned[mac] {
+			fmt.Fprintf(os.Stderr, "loop detection is disabled on %s, pass \"--enable\" to enable it\n", mac)
+			d.warned[mac] = true
+		}
+
+		if d.ports[mac] == nil {
+			d.ports[mac] = make(map[uint8]*loopPortState)
+		}
+
+		for _, metric := range device.PortMetrics {
+			state, ok := d.ports[mac][metric.ID]
+			if !ok {
+				d.ports[mac][metric.ID] = &loopPortState{metric: metric, time: now}
+				continue
+			}
+
+			elapsed := now.Sub(state.time).Seconds()
+			rate := float64(metric.Sub(state.metric).BroadcastPackets) / elapsed
+			state.metric = metric
+			state.time = now
+
+			if rate >= float64(loopThreshold) {
+				state.hits++
+			} else {
+				state.hits = 0
+			}
+
+			if state.hits >= loopSamples && !state.looping {
+				state.looping = true
+				emit(d.sink, newEvent(event.TypeLoop, device, metric.ID, fmt.Sprintf("port %d: loop suspected, receiving %.0f broadcast packets/s", metric.ID, rate)))
+			}
+
+			if state.hits == 0 && state.looping {
+				state.looping = false
+				emit(d.sink, newEvent(event.TypeLoopCleared, device, metric.ID, fmt.Sprintf("port %d: loop cleared, receiving %.0f broadcast packets/s", metric.ID, rate)))
+			}
+		}
+	}
+}
+
+func init() {
+	watchLoopsCmd.Flags().BoolVar(&loopEnable, "enable", false, "enable loop detection on the devices")
+	watchLoopsCmd.Flags().StringVarP(&password, "password", "p", "", "password to use for authentication")
+	watchLoopsCmd.Flags().Uint64Var(&loopThreshold, "threshold", 1000, "broadcast packets per second that indicate a loop")
+	watchLoopsCmd.Flags().UintVar(&loopSamples, "samples", 3, "consecutive samples above the threshold that indicate a loop")
+
+	watchCmd.AddCommand(watchLoopsCmd)
+}
@@ -1,8 +1,12 @@
 module github.com/nicklasfrahm/netadm
 
-go 1.19
+go 1.21
 
-require github.com/spf13/cobra v1.8.0
+require (
+	github.com/spf13/cobra v1.8.0
+	go.opentelemetry.io/otel v1.28.0
+	go.opentelemetry.io/otel/trace v1.28.0
+)
 
 require (
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
@@ -0,0 +1,48 @@
+package event
+
+import "time"
+
+// Type describes what kind of change an event reports.
+type Type string
+
+const (
+	// TypeLoop is emitted if a loop was detected on a port.
+	TypeLoop Type = "loop"
+	// TypeLoopCleared is emitted if a previously detected loop disappeared.
+	TypeLoopCleared Type = "loop-cleared"
+	// TypeLink is emitted if the link status of a port changed.
+	TypeLink Type = "link"
+)
+
+// Event describes a change that was observed on a device.
+type Event struct {
+	Time    time.Time `json:"time"`
+	Type    Type      `json:"type"`
+	Device  string    `json:"device"`
+	MAC     string    `json:"mac"`
+	IP      string    `json:"ip"`
+	Port    uint8     `json:"port,omitempty"`
+	From    string    `json:"from,omitempty"`
+	To      string    `json:"to,omitempty"`
+	Message string    `json:"message"`
+}
+
+// Sink is a destination that events can be emitted to.
+type Sink interface {
+	Emit(event Event) error
+}
+
+// MultiSink emits events to multiple sinks.
+type MultiSink []Sink
+
+// Emit emits the event to all sinks. It does not stop if a sink
+// fails, but returns the first error after all sinks were called.
+func (m MultiSink) Emit(event Event) error {
+	var first error
+	for _, sink := range m {
+		if err := sink.Emit(event); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
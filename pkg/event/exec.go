@@ -0,0 +1,53 @@
+package event
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+)
+
+// ExecSink runs a command for every event.
+type ExecSink struct {
+	command string
+}
+
+// NewExecSink creates a sink that runs the command via the system shell
+// for every event. The event is passed as JSON via stdin and its fields
+// are also exposed as environment variables prefixed with "NETADM_".
+func NewExecSink(command string) *ExecSink {
+	return &ExecSink{command: command}
+}
+
+// Emit runs the command and waits for it to finish.
+func (s *ExecSink) Emit(event Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", s.command)
+	} else {
+		cmd = exec.Command("sh", "-c", s.command)
+	}
+
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(),
+		"NETADM_EVENT_TYPE="+string(event.Type),
+		"NETADM_EVENT_DEVICE="+event.Device,
+		"NETADM_EVENT_MAC="+event.MAC,
+		"NETADM_EVENT_IP="+event.IP,
+		fmt.Sprintf("NETADM_EVENT_PORT=%d", event.Port),
+		"NETADM_EVENT_FROM="+event.From,
+		"NETADM_EVENT_TO="+event.To,
+		"NETADM_EVENT_MESSAGE="+event.Message,
+	)
+
+	return cmd.Run()
+}
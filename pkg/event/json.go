@@ -0,0 +1,28 @@
+package event
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+)
+
+// JSONSink writes events as JSON lines.
+type JSONSink struct {
+	mutex   sync.Mutex
+	encoder *json.Encoder
+}
+
+// NewJSONSink creates a sink that writes events as JSON lines to the writer.
+func NewJSONSink(output io.Writer) *JSONSink {
+	return &JSONSink{
+		encoder: json.NewEncoder(output),
+	}
+}
+
+// Emit writes the event as a single line of JSON.
+func (s *JSONSink) Emit(event Event) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.encoder.Encode(event)
+}
@@ -0,0 +1,40 @@
+//go:build !windows && !plan9
+
+package event
+
+import (
+	"encoding/json"
+	"log/syslog"
+)
+
+// SyslogSink writes events to syslog.
+type SyslogSink struct {
+	writer *syslog.Writer
+}
+
+// NewSyslogSink creates a sink that writes events to syslog. If the
+// address is empty, the events are written to the local syslog daemon.
+// Otherwise the events are sent via UDP to the remote syslog server.
+func NewSyslogSink(address string) (*SyslogSink, error) {
+	network := ""
+	if address != "" {
+		network = "udp"
+	}
+
+	writer, err := syslog.Dial(network, address, syslog.LOG_WARNING|syslog.LOG_DAEMON, "netadm")
+	if err != nil {
+		return nil, err
+	}
+
+	return &SyslogSink{writer: writer}, nil
+}
+
+// Emit writes the event as JSON to syslog.
+func (s *SyslogSink) Emit(event Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	return s.writer.Warning(string(payload))
+}
@@ -0,0 +1,21 @@
+//go:build windows || plan9
+
+package event
+
+import "errors"
+
+// ErrSyslogUnsupported is returned if syslog is not supported on the platform.
+var ErrSyslogUnsupported = errors.New("syslog is not supported on this platform")
+
+// SyslogSink writes events to syslog.
+type SyslogSink struct{}
+
+// NewSyslogSink always fails as syslog is not supported on this platform.
+func NewSyslogSink(address string) (*SyslogSink, error) {
+	return nil, ErrSyslogUnsupported
+}
+
+// Emit always fails as syslog is not supported on this platform.
+func (s *SyslogSink) Emit(event Event) error {
+	return ErrSyslogUnsupported
+}
@@ -0,0 +1,44 @@
+package event
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// WebhookSink posts events as JSON to a URL.
+type WebhookSink struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookSink creates a sink that posts events as JSON to the URL.
+func NewWebhookSink(url string) *WebhookSink {
+	return &WebhookSink{
+		url: url,
+		// Make sure a slow webhook can't block the watch loop forever.
+		client: &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// Emit posts the event to the webhook.
+func (s *WebhookSink) Emit(event Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	res, err := s.client.Post(s.url, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("webhook failed with status code %d", res.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,53 @@
+package fmt
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/nicklasfrahm/netadm/pkg/nsdp"
+)
+
+// Failures prints a summary of the devices that
+// reported a failure and returns their number.
+func Failures(output io.Writer, results []nsdp.Result) int {
+	failed := 0
+	for _, result := range results {
+		if result.Err != nil {
+			failed++
+		}
+	}
+	if failed == 0 {
+		return 0
+	}
+
+	fmt.Fprintf(output, "%d of %d devices failed:\n", failed, len(results))
+
+	// Create table with tabwriter.
+	w := tabwriter.NewWriter(output, 0, 0, 4, ' ', tabwriter.TabIndent)
+	fmt.Fprintf(w, "MAC\tIP\tRESULT\tERROR\n")
+
+	for _, result := range results {
+		if result.Err == nil {
+			continue
+		}
+
+		code := "-"
+		message := result.Err.Error()
+		var opErr *nsdp.OperationError
+		if errors.As(result.Err, &opErr) {
+			code = opErr.Code.String()
+			message = opErr.Err.Error()
+			if opErr.Record != 0 {
+				message += fmt.Sprintf(" at record %s", opErr.Record)
+			}
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", result.Device.MAC, result.Device.IP, code, message)
+	}
+
+	w.Flush()
+
+	return failed
+}
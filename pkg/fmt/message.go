@@ -0,0 +1,75 @@
+package fmt
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/nicklasfrahm/netadm/pkg/nsdp"
+)
+
+// Message prints the header and the records of a message
+// including their raw and their decoded values.
+func Message(output io.Writer, msg *nsdp.Message) {
+	header := msg.Header
+
+	// Create table with tabwriter.
+	w := tabwriter.NewWriter(output, 0, 0, 4, ' ', tabwriter.TabIndent)
+	fmt.Fprintf(w, "VERSION:\t%d\n", header.Version)
+	fmt.Fprintf(w, "OPERATION:\t%s\n", header.Operation)
+	fmt.Fprintf(w, "RESULT:\t%s\n", nsdp.ResponseCode(header.Result))
+	if header.Result != 0 {
+		fmt.Fprintf(w, "RECORD:\t%s\n", header.Record)
+	}
+	fmt.Fprintf(w, "CLIENT:\t%s\n", net.HardwareAddr(header.ClientMAC[:]))
+	fmt.Fprintf(w, "SERVER:\t%s\n", net.HardwareAddr(header.ServerMAC[:]))
+	fmt.Fprintf(w, "SEQUENCE:\t%d\n", header.Sequence)
+	fmt.Fprintf(w, "SIGNATURE:\t%q\n", string(header.Signature[:]))
+	w.Flush()
+
+	if len(msg.Records) == 0 {
+		return
+	}
+
+	fmt.Fprintln(output)
+	w = tabwriter.NewWriter(output, 0, 0, 4, ' ', tabwriter.TabIndent)
+	fmt.Fprintf(w, "ID\tNAME\tLEN\tRAW\tVALUE\n")
+
+	for _, record := range msg.Records {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\n",
+			record.ID,
+			recordName(record),
+			record.Len,
+			hex.EncodeToString(record.Value),
+			recordValue(record),
+		)
+	}
+	w.Flush()
+}
+
+// recordName returns the name of the record type of the record.
+func recordName(record nsdp.Record) string {
+	rt := record.Type()
+	if rt == nil {
+		return "<unknown>"
+	}
+	return strings.ToLower(rt.Name)
+}
+
+// recordValue returns the decoded value of the record. Records
+// without value, such as in read requests, have no decoded value.
+func recordValue(record nsdp.Record) string {
+	rt := record.Type()
+	if rt == nil || rt.Example == nil || len(record.Value) == 0 {
+		return "-"
+	}
+
+	value, err := record.Reflect()
+	if err != nil {
+		return fmt.Sprintf("<%v>", err)
+	}
+	return fmt.Sprintf("%v", value)
+}
@@ -0,0 +1,20 @@
+package fmt
+
+import "fmt"
+
+// Rate formats a rate in bits per second using the
+// largest decimal unit that keeps the value above 1.
+func Rate(bps float64) string {
+	units := []string{"bit/s", "kbit/s", "Mbit/s", "Gbit/s"}
+
+	unit := 0
+	for bps >= 1000 && unit < len(units)-1 {
+		bps /= 1000
+		unit++
+	}
+
+	if unit == 0 {
+		return fmt.Sprintf("%.0f %s", bps, units[unit])
+	}
+	return fmt.Sprintf("%.1f %s", bps, units[unit])
+}
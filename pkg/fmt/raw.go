@@ -0,0 +1,37 @@
+package fmt
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/nicklasfrahm/netadm/pkg/nsdp"
+)
+
+// Raw prints the records of unknown record types,
+// which the devices returned, as hexadecimal values.
+func Raw(output io.Writer, devices []nsdp.Device) {
+	// Create table with tabwriter.
+	w := tabwriter.NewWriter(output, 0, 0, 4, ' ', tabwriter.TabIndent)
+	fmt.Fprintf(w, "MAC\tID\tLEN\tVALUE\n")
+
+	for _, device := range devices {
+		// Sort record type IDs to get consistent results.
+		ids := make([]nsdp.RecordTypeID, 0, len(device.Unknown))
+		for id := range device.Unknown {
+			ids = append(ids, id)
+		}
+		sort.Slice(ids, func(i, j int) bool {
+			return ids[i] < ids[j]
+		})
+
+		for _, id := range ids {
+			value := device.Unknown[id]
+			fmt.Fprintf(w, "%s\t%s\t%d\t%s\n", device.MAC, id, len(value), hex.EncodeToString(value))
+		}
+	}
+
+	w.Flush()
+}
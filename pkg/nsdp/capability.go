@@ -0,0 +1,272 @@
+package nsdp
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Capability describes the features that a model supports
+// within a range of firmware versions.
+type Capability struct {
+	// Model is the model name as reported via RecordModel.
+	Model string
+	// FirmwareMin is the lowest firmware version the capability applies
+	// to. An empty version does not restrict the range.
+	FirmwareMin string
+	// FirmwareMax is the highest firmware version the capability applies
+	// to. An empty version does not restrict the range.
+	FirmwareMax string
+	// PortCount is the number of ports of the model.
+	PortCount uint8
+	// PoEBudget is the total power in watts that the model can supply
+	// via PoE. It is zero if the model does not support PoE.
+	PoEBudget uint16
+	// PasswordEncryption is the password encryption mode of the model.
+	// It is used if the device does not report its encryption mode.
+	PasswordEncryption EncryptionMode
+	// Records are the feature record types that the model supports
+	// in addition to the records every device supports.
+	Records []*RecordType
+}
+
+// baseRecords are the record types that are required to discover and
+// authenticate a device and are therefore supported by every model.
+var baseRecords = []*RecordType{
+	RecordModel,
+	RecordName,
+	RecordMAC,
+	RecordIP,
+	RecordNetmask,
+	RecordGateway,
+	RecordPassword,
+	RecordDHCP,
+	RecordFirmware,
+	RecordPasswordEncryption,
+	RecordPasswordNonce,
+	RecordPasswordHash,
+	RecordPortCount,
+	RecordEndOfMessage,
+}
+
+// systemRecords are the record types to maintain and monitor a device.
+var systemRecords = []*RecordType{
+	RecordReboot,
+	RecordFactoryReset,
+	RecordPortSpeeds,
+	RecordPortMetrics,
+	RecordCableTestResult,
+	RecordPortMirroring,
+	RecordLoopDetection,
+}
+
+// vlanPortRecords are the record types of port-based VLANs.
+var vlanPortRecords = []*RecordType{
+	RecordVLANEngine,
+	RecordVLANPort,
+	RecordVLANDelete,
+}
+
+// vlan802QRecords are the record types of 802.1Q VLANs.
+var vlan802QRecords = []*RecordType{
+	RecordVLAN802Q,
+	RecordPVIDs,
+}
+
+// qosRecords are the record types of the QoS, rate limit and storm control settings.
+var qosRecords = []*RecordType{
+	RecordQoSEngine,
+	RecordQoSPolicies,
+	RecordBandwidthLimitsIn,
+	RecordBandwidthLimitsOut,
+	RecordBroadcastFilter,
+	RecordBroadcastLimits,
+}
+
+// igmpRecords are the record types of the IGMP snooping settings.
+var igmpRecords = []*RecordType{
+	RecordIGMPSnoopingVLAN,
+	RecordMulticastFilter,
+	RecordIGMPHeaderValidation,
+	RecordIGMPRouterPorts,
+}
+
+// features combines groups of record types into the record types of a model.
+func features(groups ...[]*RecordType) []*RecordType {
+	records := make([]*RecordType, 0)
+	for _, group := range groups {
+		records = append(records, group...)
+	}
+	return records
+}
+
+// Capabilities is the registry of known models. Devices whose model
+// is not listed are not restricted, as the registry is incomplete.
+// Older GS105E units only run port-based VLANs, while 802.1Q VLANs
+// were added with the firmware of the second hardware revision. The
+// library does not implement the PoE records yet, so the PoE variants
+// only differ from the models they are based on by their PoE budget.
+var Capabilities = []Capability{
+	{Model: "GS105E", FirmwareMax: "1.5.99", PortCount: 5, PasswordEncryption: EncryptionModeSimple, Records: features(systemRecords, vlanPortRecords, qosRecords, igmpRecords)},
+	{Model: "GS105E", FirmwareMin: "1.6.0", PortCount: 5, PasswordEncryption: EncryptionModeSimple, Records: features(systemRecords, vlanPortRecords, vlan802QRecords, qosRecords, igmpRecords)},
+	{Model: "GS108E", PortCount: 8, PasswordEncryption: EncryptionModeSimple, Records: features(systemRecords, vlanPortRecords, vlan802QRecords, qosRecords, igmpRecords)},
+	{Model: "GS116E", PortCount: 16, PasswordEncryption: EncryptionModeSimple, Records: features(systemRecords, vlanPortRecords, vlan802QRecords, qosRecords, igmpRecords)},
+	{Model: "GS108PE", PortCount: 8, PoEBudget: 53, PasswordEncryption: EncryptionModeSimple, Records: features(systemRecords, vlanPortRecords, vlan802QRecords, qosRecords, igmpRecords)},
+	{Model: "GS305E", PortCount: 5, PasswordEncryption: EncryptionModeHash64, Records: features(systemRecords, vlanPortRecords, vlan802QRecords, qosRecords, igmpRecords)},
+	{Model: "GS308E", PortCount: 8, PasswordEncryption: EncryptionModeHash64, Records: features(systemRecords, vlanPortRecords, vlan802QRecords, qosRecords, igmpRecords)},
+	{Model: "GS305EP", PortCount: 5, PoEBudget: 63, PasswordEncryption: EncryptionModeHash64, Records: features(systemRecords, vlanPortRecords, vlan802QRecords, qosRecords, igmpRecords)},
+	{Model: "GS305EPP", PortCount: 5, PoEBudget: 120, PasswordEncryption: EncryptionModeHash64, Records: features(systemRecords, vlanPortRecords, vlan802QRecords, qosRecords, igmpRecords)},
+	{Model: "GS308EP", PortCount: 8, PoEBudget: 62, PasswordEncryption: EncryptionModeHash64, Records: features(systemRecords, vlanPortRecords, vlan802QRecords, qosRecords, igmpRecords)},
+	{Model: "GS308EPP", PortCount: 8, PoEBudget: 123, PasswordEncryption: EncryptionModeHash64, Records: features(systemRecords, vlanPortRecords, vlan802QRecords, qosRecords, igmpRecords)},
+}
+
+// LookupCapability returns the capability of a model with the given
+// firmware version. It returns nil if the model is unknown.
+func LookupCapability(model string, firmware string) *Capability {
+	for i, c := range Capabilities {
+		if !strings.EqualFold(c.Model, model) {
+			continue
+		}
+
+		if c.FirmwareMin != "" && compareFirmware(firmware, c.FirmwareMin) < 0 {
+			continue
+		}
+
+		if c.FirmwareMax != "" && compareFirmware(firmware, c.FirmwareMax) > 0 {
+			continue
+		}
+
+		return &Capabilities[i]
+	}
+
+	return nil
+}
+
+// Supports returns true if the model supports the record type.
+func (c *Capability) Supports(rt *RecordType) bool {
+	if isBaseRecord(rt) {
+		return true
+	}
+
+	for _, record := range c.Records {
+		if record == rt {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Check returns an error for the first record type that is not supported.
+func (c *Capability) Check(rts ...*RecordType) error {
+	for _, rt := range rts {
+		if !c.Supports(rt) {
+			return fmt.Errorf(`%w %s: "%s"`, ErrUnsupportedKey, c.Model, strings.ToLower(rt.Name))
+		}
+	}
+
+	return nil
+}
+
+// isBaseRecord returns true if the record type is supported by every model.
+func isBaseRecord(rt *RecordType) bool {
+	for _, record := range baseRecords {
+		if record == rt {
+			return true
+		}
+	}
+
+	return false
+}
+
+// capabilityCache caches the capability of devices by their MAC and IP
+// address. It is filled from every read that includes the model and the
+// firmware, such that the capability can be checked without a request.
+var capabilityCache = struct {
+	sync.Mutex
+	devices map[string]*Capability
+}{devices: make(map[string]*Capability)}
+
+// rememberCapability caches the capability of a device if its model is known.
+func rememberCapability(device Device) {
+	if device.Model == "" {
+		return
+	}
+
+	c := LookupCapability(device.Model, device.Firmware)
+
+	capabilityCache.Lock()
+	defer capabilityCache.Unlock()
+
+	if device.MAC != nil {
+		capabilityCache.devices[device.MAC.String()] = c
+	}
+	if device.IP != nil {
+		capabilityCache.devices[device.IP.String()] = c
+	}
+}
+
+// cachedCapability returns the cached capability of the device matched by
+// the selector. The capability is nil if the model is not in the registry.
+// It returns false if the device was not read before.
+func cachedCapability(selector *Selector) (*Capability, bool) {
+	key := selector.IP.String()
+	if selector.MAC.String() != SelectorAll.MAC.String() {
+		key = selector.MAC.String()
+	}
+
+	capabilityCache.Lock()
+	defer capabilityCache.Unlock()
+
+	c, ok := capabilityCache.devices[key]
+	return c, ok
+}
+
+// isBaseOnly returns true if all record types are base records.
+func isBaseOnly(rts []*RecordType) bool {
+	for _, rt := range rts {
+		if !isBaseRecord(rt) {
+			return false
+		}
+	}
+	return true
+}
+
+// compareFirmware compares two firmware versions, such as "1.00.10",
+// numerically part by part. Non-numeric prefixes and suffixes of the
+// parts, such as in "V2.06.03EN", are ignored.
+func compareFirmware(a string, b string) int {
+	as := strings.Split(strings.TrimLeft(a, "vV"), ".")
+	bs := strings.Split(strings.TrimLeft(b, "vV"), ".")
+
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var x, y int
+		if i < len(as) {
+			x = leadingInt(as[i])
+		}
+		if i < len(bs) {
+			y = leadingInt(bs[i])
+		}
+
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	return 0
+}
+
+// leadingInt parses the leading digits of a string.
+func leadingInt(s string) int {
+	end := 0
+	for end < len(s) && s[end] >= '0' && s[end] <= '9' {
+		end++
+	}
+
+	n, _ := strconv.Atoi(s[:end])
+	return n
+}
@@ -0,0 +1,77 @@
+package nsdp
+
+import (
+	"errors"
+	"net"
+	"testing"
+)
+
+func TestCompareFirmware(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{a: "1.00.10", b: "1.00.10", want: 0},
+		{a: "1.00.9", b: "1.00.10", want: -1},
+		{a: "V2.06.03EN", b: "2.06.02", want: 1},
+		{a: "1.0", b: "1.0.0", want: 0},
+	}
+
+	for _, test := range tests {
+		if got := compareFirmware(test.a, test.b); got != test.want {
+			t.Errorf("compareFirmware(%q, %q) = %d, want %d", test.a, test.b, got, test.want)
+		}
+	}
+}
+
+func TestCapabilityCheck(t *testing.T) {
+	c := &Capability{Model: "TEST", Records: features(systemRecords)}
+
+	if err := c.Check(RecordModel, RecordReboot); err != nil {
+		t.Errorf("Check() = %v, want nil", err)
+	}
+	if err := c.Check(RecordVLAN802Q); !errors.Is(err, ErrUnsupportedKey) {
+		t.Errorf("Check() = %v, want %v", err, ErrUnsupportedKey)
+	}
+}
+
+func TestLookupCapability(t *testing.T) {
+	tests := []struct {
+		firmware string
+		key      *RecordType
+		want     error
+	}{
+		{firmware: "1.03.10", key: RecordVLANPort, want: nil},
+		{firmware: "1.03.10", key: RecordVLAN802Q, want: ErrUnsupportedKey},
+		{firmware: "1.6.0", key: RecordVLAN802Q, want: nil},
+	}
+
+	for _, test := range tests {
+		c := LookupCapability("GS105E", test.firmware)
+		if c == nil {
+			t.Fatalf("LookupCapability(%q) = nil, want capability", test.firmware)
+		}
+		if err := c.Check(test.key); !errors.Is(err, test.want) {
+			t.Errorf("LookupCapability(%q).Check(%s) = %v, want %v", test.firmware, test.key.Name, err, test.want)
+		}
+	}
+}
+
+func TestCachedCapability(t *testing.T) {
+	mac := net.HardwareAddr{0x02, 0x00, 0x00, 0x00, 0x00, 0x42}
+	ip := net.IP{192, 0, 2, 42}
+
+	selector := NewSelector().SetMAC(&mac)
+	if _, ok := cachedCapability(selector); ok {
+		t.Fatal("cachedCapability() found a device that was not read")
+	}
+
+	rememberCapability(Device{MAC: mac, IP: ip, Model: "GS308E"})
+
+	for _, selector := range []*Selector{NewSelector().SetMAC(&mac), NewSelector().SetIP(&ip)} {
+		c, ok := cachedCapability(selector)
+		if !ok || c == nil || c.Model != "GS308E" {
+			t.Errorf("cachedCapability() = %v, %t, want GS308E", c, ok)
+		}
+	}
+}
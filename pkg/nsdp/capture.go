@@ -0,0 +1,47 @@
+package nsdp
+
+import (
+	"log/slog"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/nicklasfrahm/netadm/pkg/pcap"
+)
+
+// capture holds the writer that receives all datagrams sent and received via Send.
+var capture struct {
+	sync.Mutex
+	writer *pcap.Writer
+}
+
+// SetCapture configures a writer that receives every message
+// sent and received via Send, which is useful for debugging.
+// Passing nil disables the capture.
+func SetCapture(writer *pcap.Writer) {
+	capture.Lock()
+	defer capture.Unlock()
+
+	capture.writer = writer
+}
+
+// capturePacket writes a datagram to the capture if it is enabled.
+func capturePacket(logger *slog.Logger, src *net.UDPAddr, dst *net.UDPAddr, payload []byte) {
+	capture.Lock()
+	writer := capture.writer
+	capture.Unlock()
+
+	if writer == nil {
+		return
+	}
+
+	err := writer.WritePacket(pcap.Packet{
+		Time:    time.Now(),
+		Src:     src,
+		Dst:     dst,
+		Payload: payload,
+	})
+	if err != nil {
+		logger.Warn("failed to capture packet", "error", err)
+	}
+}
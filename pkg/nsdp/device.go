@@ -1,6 +1,7 @@
 package nsdp
 
 import (
+	"errors"
 	"net"
 	"reflect"
 )
@@ -39,19 +40,45 @@ type Device struct {
 	IGMPSnoopingVLAN     IGMPSnoopingVLAN
 	MulticastFilter      bool
 	IGMPHeaderValidation bool
+	IGMPRouterPorts      IGMPRouterPorts
+	// Unknown contains the raw values of all records
+	// whose record type is not known to this library.
+	Unknown map[RecordTypeID][]byte
 }
 
 // UnmarshalMessage decodes a message into a Device.
+// It returns DecodeErrors if any of the records are malformed, but
+// still decodes all other records of the message.
 func (d *Device) UnmarshalMessage(msg *Message) error {
+	errs := make(DecodeErrors, 0)
 	for _, record := range msg.Records {
 		// Fetch record type, because it tells us which field to map it to.
+		// Keep records of unknown types instead of failing the whole message.
 		rt := record.Type()
 		if rt == nil {
-			return ErrRecordTypeUnknown
+			if d.Unknown == nil {
+				d.Unknown = make(map[RecordTypeID][]byte)
+			}
+			d.Unknown[record.ID] = record.Value
+			continue
 		}
 
-		// Dynamically decode the record type.
-		value := record.Reflect()
+		// Skip records without a value, as there is nothing to decode.
+		if len(record.Value) == 0 {
+			continue
+		}
+
+		// Dynamically decode the record type and keep decoding the
+		// other records if the value of the record is malformed.
+		value, err := record.Reflect()
+		if err != nil {
+			var decodeErr *DecodeError
+			if errors.As(err, &decodeErr) {
+				errs = append(errs, decodeErr)
+				continue
+			}
+			return err
+		}
 
 		// Set the value of the field.
 		field := reflect.ValueOf(d).Elem().FieldByName(rt.Name)
@@ -70,5 +97,9 @@ func (d *Device) UnmarshalMessage(msg *Message) error {
 		}
 	}
 
+	if len(errs) > 0 {
+		return errs
+	}
+
 	return nil
 }
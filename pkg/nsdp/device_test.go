@@ -0,0 +1,93 @@
+package nsdp
+
+import (
+	"errors"
+	"testing"
+)
+
+func FuzzDeviceUnmarshalMessage(f *testing.F) {
+	for _, seed := range fuzzSeeds(f) {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		msg := new(Message)
+		if err := msg.UnmarshalBinary(data); err != nil {
+			return
+		}
+
+		// Decoding each record on its own must not panic either.
+		for _, record := range msg.Records {
+			record.Reflect()
+		}
+
+		device := new(Device)
+		err := device.UnmarshalMessage(msg)
+		if err == nil {
+			return
+		}
+
+		var decodeErrs DecodeErrors
+		if !errors.As(err, &decodeErrs) {
+			t.Fatalf("expected DecodeErrors, got %v", err)
+		}
+
+		// Every decode error must describe a record of the buffer whose
+		// value indeed ends before the offset the decoder needs to read.
+		for _, decodeErr := range decodeErrs {
+			if !containsRecord(msg, decodeErr) {
+				t.Fatalf("decode error does not match any record of the message: %v", decodeErr)
+			}
+			if int(decodeErr.Len) > len(data)-32 {
+				t.Fatalf("decode error reports length %d for a buffer of %d bytes", decodeErr.Len, len(data))
+			}
+			if decodeErr.Offset <= int(decodeErr.Len) || decodeErr.Offset != decodeMinLen(decodeErr.RecordType.Example) {
+				t.Fatalf("decode error reports offset %d for value of length %d", decodeErr.Offset, decodeErr.Len)
+			}
+		}
+	})
+}
+
+// containsRecord returns true if the message contains a
+// record of the type and length of the decode error.
+func containsRecord(msg *Message, decodeErr *DecodeError) bool {
+	for _, record := range msg.Records {
+		if record.ID == decodeErr.RecordType.ID && int(record.Len) == int(decodeErr.Len) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestDeviceUnmarshalMessage(t *testing.T) {
+	seeds := fuzzSeeds(t)
+
+	msg := new(Message)
+	if err := msg.UnmarshalBinary(seeds[0]); err != nil {
+		t.Fatal(err)
+	}
+	device := new(Device)
+	if err := device.UnmarshalMessage(msg); err != nil {
+		t.Fatal(err)
+	}
+	if device.Model != "GS308E" || device.IP.String() != "192.168.0.253" || device.PasswordEncryption != EncryptionModeHash64 {
+		t.Errorf("unexpected device: %+v", device)
+	}
+
+	// The truncated records are reported, while the others are kept.
+	msg = new(Message)
+	if err := msg.UnmarshalBinary(seeds[len(seeds)-1]); err != nil {
+		t.Fatal(err)
+	}
+	device = new(Device)
+	var decodeErrs DecodeErrors
+	if err := device.UnmarshalMessage(msg); !errors.As(err, &decodeErrs) || len(decodeErrs) != 2 {
+		t.Fatalf("expected 2 decode errors, got %v", err)
+	}
+	if decodeErrs[0].RecordType != RecordIP || decodeErrs[0].Len != 2 || decodeErrs[0].Offset != 4 {
+		t.Errorf("unexpected decode error: %v", decodeErrs[0])
+	}
+	if len(device.Unknown[RecordTypeID(0x7400)]) != 2 {
+		t.Errorf("expected unknown record to be kept, got %v", device.Unknown)
+	}
+}
@@ -1,12 +1,19 @@
 package nsdp
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+)
 
 var (
 	// ErrRecordTypeUnknown is returned if the record type is not supported.
 	ErrRecordTypeUnknown = errors.New("record type unknown")
 	// ErrNoDevicesFound is returned if no devices responded within the timeout period.
 	ErrNoDevicesFound = errors.New("no devices found")
+	// ErrDevicesFailed is returned if some of the devices reported a failure.
+	ErrDevicesFailed = errors.New("some devices failed")
 	// ErrInvalidDeviceIdentifier is returned if the device identifier is not a MAC or an IP.
 	ErrInvalidDeviceIdentifier = errors.New("device identifier must be a MAC address or an IP address")
 	// ErrInvalidEncryptionMode is returned if the encryption mode is not supported.
@@ -19,28 +26,265 @@ var (
 	ErrInvalidInterfaceAddress = errors.New("invalid interface address")
 	// ErrInvalidSelector is returned if the selector is invalid.
 	ErrInvalidSelector = errors.New("invalid selector")
+	// ErrInvalidLogger is returned if the logger is nil.
+	ErrInvalidLogger = errors.New("invalid logger")
+	// ErrInvalidTracerProvider is returned if the tracer provider is nil.
+	ErrInvalidTracerProvider = errors.New("invalid tracer provider")
+	// ErrInvalidRetryPolicy is returned if the retry policy is nil or has negative values.
+	ErrInvalidRetryPolicy = errors.New("invalid retry policy")
 	// ErrInvalidResponse is returned if the response is invalid.
 	ErrInvalidResponse = errors.New("invalid response")
 	// ErrInvalidRecordLength is returned if the record length is invalid.
 	ErrInvalidRecordLength = errors.New("invalid record length")
+	// ErrUnsupportedRecord is returned if the device does not support a record type.
+	ErrUnsupportedRecord = errors.New("unsupported record type")
+	// ErrInvalidRecordValue is returned if the device rejects the value of a record.
+	ErrInvalidRecordValue = errors.New("invalid record value")
+	// ErrOperationFailed is returned if the device reports any other failure.
+	ErrOperationFailed = errors.New("operation failed")
 	// ErrInvalidPassword is returned if the password is invalid.
 	ErrInvalidPassword = errors.New("invalid password")
 	// ErrInvalidPasswordLockdown is returned if the password is invalid 3 times in a row.
 	ErrInvalidPasswordLockdown = errors.New("device locked due to too many invalid password attempts")
 	// ErrFailedNonceRetrieval is returned if the nonce retrieval failed.
 	ErrFailedNonceRetrieval = errors.New("failed to retrieve password encryption nonce")
+	// ErrWriteUnconfirmed is returned if the device did not confirm a write.
+	ErrWriteUnconfirmed = errors.New("device did not confirm the write")
+	// ErrMultipleDevices is returned if an operation requires a single device.
+	ErrMultipleDevices = errors.New("operation requires a single device")
+	// ErrInvalidPort is returned if a port does not exist on the device.
+	ErrInvalidPort = errors.New("invalid port")
+	// ErrInvalidVLANID is returned if a VLAN ID is outside of the valid range.
+	ErrInvalidVLANID = errors.New("invalid VLAN ID")
+	// ErrVLANNotFound is returned if a VLAN does not exist on the device.
+	ErrVLANNotFound = errors.New("VLAN not found")
+	// ErrVLANExists is returned if a VLAN already exists on the device.
+	ErrVLANExists = errors.New("VLAN already exists")
+	// ErrInvalidVLANEngine is returned if a VLAN engine name is unknown.
+	ErrInvalidVLANEngine = errors.New("invalid VLAN engine")
+	// ErrUnsupportedVLANEngine is returned if the active VLAN engine does not support the operation.
+	ErrUnsupportedVLANEngine = errors.New("operation not supported by the active VLAN engine")
+	// ErrInvalidQoSEngine is returned if a QoS engine name is unknown.
+	ErrInvalidQoSEngine = errors.New("invalid QoS engine")
+	// ErrInvalidQoSPriority is returned if a QoS priority name is unknown.
+	ErrInvalidQoSPriority = errors.New("invalid QoS priority")
+	// ErrUnsupportedQoSEngine is returned if the active QoS engine does not support the operation.
+	ErrUnsupportedQoSEngine = errors.New("operation not supported by the active QoS engine")
+	// ErrInvalidBandwidthLimit is returned if a rate can't be parsed.
+	ErrInvalidBandwidthLimit = errors.New("invalid bandwidth limit")
+	// ErrInvalidPortMirroring is returned if a port mirroring configuration is invalid.
+	ErrInvalidPortMirroring = errors.New("invalid port mirroring")
+	// ErrStrandedPort is returned if a change would make a port or the management host unreachable.
+	ErrStrandedPort = errors.New("change would strand port")
+	// ErrHostPortRequired is returned if a change could strand the management host, but its port is unknown.
+	ErrHostPortRequired = errors.New("port of the management host required")
+	// ErrInvalidIPConfig is returned if an IP address, netmask and gateway are inconsistent.
+	ErrInvalidIPConfig = errors.New("invalid IP configuration")
+	// ErrInvalidName is returned if a device name is too long or contains invalid characters.
+	ErrInvalidName = errors.New("invalid device name")
+	// ErrUnsupportedKey is returned if the model of a device does not support a configuration key.
+	ErrUnsupportedKey = errors.New("configuration key not supported by model")
 )
 
+// DecodeError is returned if the value of a record is too short
+// to be decoded into the type of the record type.
+type DecodeError struct {
+	// RecordType is the type of the record that could not be decoded.
+	RecordType *RecordType
+	// Len is the length of the record value.
+	Len uint16
+	// Offset is the offset up to which the decoder needs to read the value.
+	Offset int
+}
+
+// Error returns the error message of the decode error.
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("failed to decode record type %s (%s): value of length %d ends before offset %d",
+		e.RecordType.ID, e.RecordType.Name, e.Len, e.Offset)
+}
+
+// DecodeErrors aggregates the decode errors of all records of a message.
+type DecodeErrors []*DecodeError
+
+// Error returns the error messages of all decode errors.
+func (e DecodeErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// OperationError describes a failed operation of a device. It wraps one
+// of the sentinel errors of this package, such that errors.Is can be used.
+type OperationError struct {
+	// MAC is the MAC address of the device that reported the error.
+	MAC net.HardwareAddr
+	// IP is the IP address of the device that reported the error.
+	IP net.IP
+	// Operation is the operation of the failed request.
+	Operation OpCode
+	// Code is the response code that the device returned.
+	Code ResponseCode
+	// Record is the ID of the record that caused the error. It is
+	// zero if the device did not report an offending record.
+	Record RecordTypeID
+	// Attempt is the attempt during which the error occurred. It
+	// is zero if the operation was not retried by this library.
+	Attempt uint
+	// Err is the sentinel error that describes the failure.
+	Err error
+}
+
+// Error returns the error message of the operation error.
+func (e *OperationError) Error() string {
+	message := fmt.Sprintf("%s failed on device %s", e.Operation, e.MAC)
+	if e.IP != nil {
+		message += fmt.Sprintf(" (%s)", e.IP)
+	}
+	message += fmt.Sprintf(": %v [%s]", e.Err, e.Code)
+	if e.Record != 0 {
+		message += fmt.Sprintf(" at record %s", e.Record)
+	}
+	if e.Attempt != 0 {
+		message += fmt.Sprintf(" during attempt %d", e.Attempt)
+	}
+	return message
+}
+
+// Unwrap returns the sentinel error of the operation error.
+func (e *OperationError) Unwrap() error {
+	return e.Err
+}
+
+// OperationErrors aggregates the errors of all devices that
+// reported a failure in response to the same request.
+type OperationErrors []*OperationError
+
+// Error returns the error messages of all operation errors.
+func (e OperationErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Unwrap returns all operation errors, such that
+// errors.Is and errors.As inspect each of them.
+func (e OperationErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, err := range e {
+		errs[i] = err
+	}
+	return errs
+}
+
+// setAttempt records the attempt in all operation errors.
+func setAttempt(err error, attempt uint) {
+	var opErrs OperationErrors
+	if errors.As(err, &opErrs) {
+		for _, opErr := range opErrs {
+			if opErr.Attempt == 0 {
+				opErr.Attempt = attempt
+			}
+		}
+		return
+	}
+
+	var opErr *OperationError
+	if errors.As(err, &opErr) && opErr.Attempt == 0 {
+		opErr.Attempt = attempt
+	}
+}
+
 // ResponseCode describes the response code of a NSDP message.
 type ResponseCode uint16
 
 const (
+	// ResponseCodeSuccess is returned if the operation succeeded.
+	ResponseCodeSuccess ResponseCode = 0x0000
+	// ResponseCodeUnsupportedVersion is returned if the protocol version is not supported.
+	ResponseCodeUnsupportedVersion ResponseCode = 0x0100
+	// ResponseCodeUnsupportedOperation is returned if the operation is not supported.
+	ResponseCodeUnsupportedOperation ResponseCode = 0x0200
+	// ResponseCodeUnsupportedRecord is returned if a record type is not supported.
+	ResponseCodeUnsupportedRecord ResponseCode = 0x0300
 	// ResponseCodeInvalidRecordLength is returned when the record length is invalid.
 	ResponseCodeInvalidRecordLength ResponseCode = 0x0400
+	// ResponseCodeInvalidRecordValue is returned when the record value is invalid.
+	ResponseCodeInvalidRecordValue ResponseCode = 0x0500
+	// ResponseCodeIPNotAllowed is returned if the host IP is not allowed to manage the device.
+	ResponseCodeIPNotAllowed ResponseCode = 0x0600
+	// ResponseCodeManagementDisabled is returned if the management via NSDP is disabled.
+	ResponseCodeManagementDisabled ResponseCode = 0x0F00
 	// ResponseCodeInvalidPassword is returned when the password is invalid.
 	ResponseCodeInvalidPassword ResponseCode = 0x0D00
 	// ResponseCodeInvalidPasswordLockdown is returned if the user provides the wrong
 	// password during 3 separate attempts. The devices enters a lockdown state for 30
 	// minutes, during which it will not respond to any requests.
 	ResponseCodeInvalidPasswordLockdown ResponseCode = 0x0E00
+	// ResponseCodeTFTPCallError is returned if a firmware download could not be started.
+	ResponseCodeTFTPCallError ResponseCode = 0x8100
+	// ResponseCodeTFTPOutOfMemory is returned if the device ran out of memory during a firmware download.
+	ResponseCodeTFTPOutOfMemory ResponseCode = 0x8200
+	// ResponseCodeFirmwareUpgradeFailed is returned if a firmware upgrade failed.
+	ResponseCodeFirmwareUpgradeFailed ResponseCode = 0x8300
+	// ResponseCodeTFTPTimeout is returned if a firmware download timed out.
+	ResponseCodeTFTPTimeout ResponseCode = 0x8400
+	// ResponseCodeScheduled is returned if the operation is scheduled for later execution.
+	ResponseCodeScheduled ResponseCode = 0xF000
+	// ResponseCodeInProgress is returned if the operation is still in progress.
+	ResponseCodeInProgress ResponseCode = 0xF100
+	// ResponseCodeTFTPInProgress is returned if a firmware download is still in progress.
+	ResponseCodeTFTPInProgress ResponseCode = 0xF200
 )
+
+// responseCodeNames maps the response codes to their names.
+var responseCodeNames = map[ResponseCode]string{
+	ResponseCodeSuccess:                 "Success",
+	ResponseCodeUnsupportedVersion:      "UnsupportedVersion",
+	ResponseCodeUnsupportedOperation:    "UnsupportedOperation",
+	ResponseCodeUnsupportedRecord:       "UnsupportedRecord",
+	ResponseCodeInvalidRecordLength:     "InvalidRecordLength",
+	ResponseCodeInvalidRecordValue:      "InvalidRecordValue",
+	ResponseCodeIPNotAllowed:            "IPNotAllowed",
+	ResponseCodeManagementDisabled:      "ManagementDisabled",
+	ResponseCodeInvalidPassword:         "InvalidPassword",
+	ResponseCodeInvalidPasswordLockdown: "InvalidPasswordLockdown",
+	ResponseCodeTFTPCallError:           "TFTPCallError",
+	ResponseCodeTFTPOutOfMemory:         "TFTPOutOfMemory",
+	ResponseCodeFirmwareUpgradeFailed:   "FirmwareUpgradeFailed",
+	ResponseCodeTFTPTimeout:             "TFTPTimeout",
+	ResponseCodeScheduled:               "Scheduled",
+	ResponseCodeInProgress:              "InProgress",
+	ResponseCodeTFTPInProgress:          "TFTPInProgress",
+}
+
+// String returns the name of the response code.
+func (c ResponseCode) String() string {
+	if name, ok := responseCodeNames[c]; ok {
+		return name
+	}
+	return fmt.Sprintf("Unknown(0x%04X)", uint16(c))
+}
+
+// Err returns the sentinel error that describes the response code.
+func (c ResponseCode) Err() error {
+	switch c {
+	case ResponseCodeSuccess:
+		return nil
+	case ResponseCodeUnsupportedRecord:
+		return ErrUnsupportedRecord
+	case ResponseCodeInvalidRecordLength:
+		return ErrInvalidRecordLength
+	case ResponseCodeInvalidRecordValue:
+		return ErrInvalidRecordValue
+	case ResponseCodeInvalidPassword:
+		return ErrInvalidPassword
+	case ResponseCodeInvalidPasswordLockdown:
+		return ErrInvalidPasswordLockdown
+	default:
+		return ErrOperationFailed
+	}
+}
@@ -0,0 +1,92 @@
+package nsdp
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+)
+
+func TestResponseCodeErr(t *testing.T) {
+	tests := []struct {
+		code ResponseCode
+		err  error
+	}{
+		{code: ResponseCodeSuccess, err: nil},
+		{code: ResponseCodeUnsupportedRecord, err: ErrUnsupportedRecord},
+		{code: ResponseCodeInvalidRecordLength, err: ErrInvalidRecordLength},
+		{code: ResponseCodeInvalidRecordValue, err: ErrInvalidRecordValue},
+		{code: ResponseCodeInvalidPassword, err: ErrInvalidPassword},
+		{code: ResponseCodeInvalidPasswordLockdown, err: ErrInvalidPasswordLockdown},
+		{code: ResponseCodeManagementDisabled, err: ErrOperationFailed},
+		{code: ResponseCode(0x1234), err: ErrOperationFailed},
+	}
+
+	for _, test := range tests {
+		if err := test.code.Err(); err != test.err {
+			t.Errorf("%s.Err() = %v, want %v", test.code, err, test.err)
+		}
+	}
+}
+
+func TestResponseCodeString(t *testing.T) {
+	if s := ResponseCodeInvalidPassword.String(); s != "InvalidPassword" {
+		t.Errorf("expected InvalidPassword, got %s", s)
+	}
+	if s := ResponseCode(0x1234).String(); s != "Unknown(0x1234)" {
+		t.Errorf("expected Unknown(0x1234), got %s", s)
+	}
+}
+
+func TestOperationErrorsUnwrap(t *testing.T) {
+	passwordErr := &OperationError{
+		MAC:       net.HardwareAddr{0x02, 0x00, 0x00, 0x00, 0x00, 0x01},
+		IP:        net.IP{192, 0, 2, 1},
+		Operation: WriteRequest,
+		Code:      ResponseCodeInvalidPassword,
+		Err:       ResponseCodeInvalidPassword.Err(),
+	}
+	recordErr := &OperationError{
+		MAC:       net.HardwareAddr{0x02, 0x00, 0x00, 0x00, 0x00, 0x02},
+		Operation: ReadRequest,
+		Code:      ResponseCodeUnsupportedRecord,
+		Record:    RecordLoopDetection.ID,
+		Err:       ResponseCodeUnsupportedRecord.Err(),
+	}
+
+	err := fmt.Errorf("failed to read: %w", OperationErrors{passwordErr, recordErr})
+
+	for _, sentinel := range []error{ErrInvalidPassword, ErrUnsupportedRecord} {
+		if !errors.Is(err, sentinel) {
+			t.Errorf("expected error to wrap %v", sentinel)
+		}
+	}
+	if errors.Is(err, ErrInvalidPasswordLockdown) {
+		t.Errorf("expected error not to wrap %v", ErrInvalidPasswordLockdown)
+	}
+
+	var opErr *OperationError
+	if !errors.As(err, &opErr) || opErr != passwordErr {
+		t.Errorf("expected errors.As to find the first operation error, got %v", opErr)
+	}
+
+	var opErrs OperationErrors
+	if !errors.As(err, &opErrs) || len(opErrs) != 2 {
+		t.Errorf("expected errors.As to find both operation errors, got %v", opErrs)
+	}
+}
+
+func TestSetAttempt(t *testing.T) {
+	first := &OperationError{Err: ErrOperationFailed}
+	second := &OperationError{Err: ErrOperationFailed, Attempt: 1}
+
+	setAttempt(OperationErrors{first, second}, 2)
+	if first.Attempt != 2 || second.Attempt != 1 {
+		t.Errorf("expected attempts 2 and 1, got %d and %d", first.Attempt, second.Attempt)
+	}
+
+	if !strings.Contains(first.Error(), "during attempt 2") {
+		t.Errorf("expected attempt in error message, got %q", first.Error())
+	}
+}
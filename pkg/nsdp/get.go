@@ -2,83 +2,281 @@ package nsdp
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"net"
 	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // Get provides a simplified way to fetch configuration keys from devices.
+// It fails if any of the devices reports a failure. Use GetResults to
+// obtain the configuration of all other devices in this case.
 func Get(id string, keys []string, options ...Option) ([]Device, error) {
+	results, err := GetResults(id, keys, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	devices := make([]Device, 0, len(results))
+	failures := make(OperationErrors, 0)
+	for _, result := range results {
+		var opErr *OperationError
+		if errors.As(result.Err, &opErr) {
+			failures = append(failures, opErr)
+			continue
+		}
+		devices = append(devices, result.Device)
+	}
+
+	if len(failures) > 0 {
+		return nil, failures
+	}
+
+	return devices, nil
+}
+
+// GetResults fetches configuration keys from devices and returns a result
+// for every device that responded. If a device reports a failure during
+// all attempts, its result contains an OperationError, while the results
+// of all other devices are still returned.
+func GetResults(id string, keys []string, options ...Option) (results []Result, err error) {
 	// Get operation options.
 	opts, err := GetDefaultOptions().Apply(options...)
 	if err != nil {
 		return nil, err
 	}
 
-	selector := NewSelector()
-	// Allow usage of keyword "all" to select all devices.
-	if id != "all" {
-		// Check if the device is identified via its IP address.
-		ip := net.ParseIP(id)
-		if ip == nil {
-			// Fall back to MAC address device identification.
-			mac, err := net.ParseMAC(id)
-			if err != nil {
-				return nil, ErrInvalidDeviceIdentifier
-			}
-			selector.SetMAC(&mac)
-		} else {
-			selector.SetIP(&ip)
-		}
+	spanCtx, span := startSpan(opts.Context, opts, "nsdp.Get",
+		attribute.String("nsdp.device", id),
+		attribute.StringSlice("nsdp.keys", keys),
+	)
+	defer func() {
+		span.SetAttributes(attribute.Int("nsdp.devices", len(results)))
+		endSpan(span, err)
+	}()
+
+	selector, err := ParseSelector(id)
+	if err != nil {
+		return nil, err
 	}
 
 	// Check if all keys are valid.
+	rts := make([]*RecordType, len(keys))
 	for i, key := range keys {
 		// Normalize key name.
 		keys[i] = strings.ToLower(key)
 
 		// Check if key is valid.
-		if RecordTypeByName[key] == nil {
+		rts[i] = RecordTypeByName[keys[i]]
+		if rts[i] == nil {
 			return nil, fmt.Errorf(`unknown configuration key "%s"`, key)
 		}
 	}
 
-	// Create slice to hold results.
+	// Reject keys that are not supported by the model of the device. If
+	// the model is not known yet, it is read along with the keys and
+	// checked afterwards, which avoids an additional request.
+	readKeys := keys
+	checkModel := false
+	if !selector.IsAll() && !isBaseOnly(rts) {
+		if c, ok := cachedCapability(selector); !ok {
+			readKeys = appendMissing(keys, "model", "firmware")
+			checkModel = true
+		} else if c != nil {
+			if err := c.Check(rts...); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// Create slice and map to hold results.
 	devices := make([]Device, 0)
+	failures := make(map[string]*OperationError)
+
+	// An explicitly selected device ends an attempt as soon as it responded.
+	limit := uint(0)
+	if !selector.IsAll() {
+		limit = 1
+	}
 
 	// Retry operation if retries is greater than 0.
-	for i := uint(0); i <= opts.Retries; i++ {
+	attempts := opts.Retries + 1
+	warmedUp := !opts.Warmup
+
+	// A scan can't tell whether a device with a cold ARP cache missed the
+	// first request, as other devices may have responded. The first request
+	// primes the ARP cache of all devices, so a scan is repeated once.
+	if selector.IsAll() && opts.Warmup {
+		warmedUp = true
+		if attempts < 2 {
+			attempts = 2
+		}
+	}
+	for i := uint(0); i < attempts; i++ {
+		// Back off before retrying.
+		if delay := opts.RetryPolicy.Delay(i); delay > 0 {
+			opts.Logger.Debug("backing off", "device", id, "attempt", i+1, "delay", delay)
+			if err := sleep(spanCtx, delay); err != nil {
+				return nil, err
+			}
+		}
+
+		timeout := opts.attemptTimeout(selector, i+1)
+		opts.Logger.Debug("reading records", "device", id, "keys", readKeys, "attempt", i+1, "attempts", attempts, "timeout", timeout)
+
 		// Create new message.
 		request := NewMessage(ReadRequest)
 
 		// Add request records.
-		for _, key := range keys {
+		for _, key := range readKeys {
 			request.Records = append(request.Records, Record{
 				ID: RecordTypeByName[key].ID,
 			})
 		}
 
 		// Create context to handle timeout.
-		ctx, cancel := context.WithTimeout(context.Background(), opts.Timeout)
+		ctx, cancel := context.WithTimeout(spanCtx, timeout)
 		defer cancel()
 
+		ctx, attemptSpan := startSpan(ctx, opts, "nsdp.Get.Attempt",
+			attribute.Int("nsdp.attempt", int(i+1)),
+		)
+
 		// Run scan for devices.
 		devs, err := RequestDevices(opts.InterfaceName, request,
 			WithContext(ctx),
 			WithSelector(selector),
+			WithRetryPolicy(opts.RetryPolicy),
+			WithResponseLimit(limit),
+			WithLogger(opts.Logger),
+			WithTracerProvider(opts.TracerProvider),
 		)
-		if err != nil {
+		setAttempt(err, i+1)
+		attemptSpan.SetAttributes(attribute.Int("nsdp.responses", len(devs)))
+		endSpan(attemptSpan, err)
+
+		// Remember failed devices, but keep the other devices.
+		var opErrs OperationErrors
+		if errors.As(err, &opErrs) {
+			for _, opErr := range opErrs {
+				failures[opErr.MAC.String()] = opErr
+			}
+		} else if err != nil {
 			return nil, err
 		}
 
 		// Deduplicate results from all attempts.
+		previous := len(devices)
 		devices = DeduplicateDevices(devices, devs)
+		opts.Logger.Debug("attempt completed", "device", id, "attempt", i+1, "responses", len(devs), "devices", len(devices))
+
+		// Prime the ARP cache of the devices if nothing responded to the
+		// first attempt and repeat the operation if this succeeded.
+		if !warmedUp && len(devs) == 0 && len(opErrs) == 0 {
+			warmedUp = true
+
+			primed, err := warmup(spanCtx, opts, selector, limit)
+			if err != nil {
+				return nil, err
+			}
+			if primed {
+				opts.Logger.Debug("device responded to priming read", "device", id)
+				attempts++
+				continue
+			}
+		}
+
+		// Skip the remaining attempts once the selected device succeeded.
+		if limit > 0 && len(devices) >= int(limit) {
+			break
+		}
+
+		// Skip the remaining attempts of a scan once an attempt did
+		// not find any new devices within the quiet period.
+		if limit == 0 && opts.RetryPolicy.QuietPeriod > 0 && previous > 0 && len(devices) == previous {
+			opts.Logger.Debug("no new devices found", "device", id, "attempt", i+1)
+			break
+		}
+	}
+
+	for _, device := range devices {
+		rememberCapability(device)
+	}
+
+	if checkModel && len(devices) == 1 {
+		if c := LookupCapability(devices[0].Model, devices[0].Firmware); c != nil {
+			if err := c.Check(rts...); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// A device may reject an unsupported key with a response code, in
+	// which case the model is read separately to report a clear error.
+	if checkModel && len(devices) == 0 {
+		for _, failure := range failures {
+			device, err := getDevice(failure.MAC.String(), []string{"model", "firmware"}, options...)
+			if err != nil {
+				continue
+			}
+
+			if c := LookupCapability(device.Model, device.Firmware); c != nil {
+				if err := c.Check(rts...); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	// A device only failed if it did not succeed during any attempt.
+	results = make([]Result, 0, len(devices)+len(failures))
+	for _, device := range devices {
+		delete(failures, device.MAC.String())
+		results = append(results, Result{Device: device})
+	}
+	for _, failure := range failures {
+		results = append(results, Result{
+			Device: Device{MAC: failure.MAC, IP: failure.IP},
+			Err:    failure,
+		})
 	}
 
 	// Check if any devices were found.
-	if len(devices) == 0 {
+	if len(results) == 0 {
+		opts.Logger.Debug("no devices responded", "device", id)
 		return nil, ErrNoDevicesFound
 	}
 
-	return devices, nil
+	return results, nil
+}
+
+// appendMissing returns a copy of the keys with the given keys appended,
+// unless they are already included.
+func appendMissing(keys []string, missing ...string) []string {
+	result := append([]string{}, keys...)
+	for _, key := range missing {
+		found := false
+		for _, k := range keys {
+			found = found || k == key
+		}
+		if !found {
+			result = append(result, key)
+		}
+	}
+	return result
+}
+
+// getDevice fetches the configuration keys from a single device.
+func getDevice(id string, keys []string, options ...Option) (*Device, error) {
+	devices, err := Get(id, keys, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(devices) != 1 {
+		return nil, ErrMultipleDevices
+	}
+
+	return &devices[0], nil
 }
@@ -0,0 +1,42 @@
+package nsdp
+
+// EnableIGMPSnooping enables IGMP snooping for the given VLAN of a device.
+func EnableIGMPSnooping(id string, vlan uint16, options ...Option) ([]Device, error) {
+	if err := checkVLANID(vlan); err != nil {
+		return nil, err
+	}
+
+	return SetRecords(id, []Record{IGMPSnoopingVLAN(vlan).Record()}, options...)
+}
+
+// DisableIGMPSnooping disables IGMP snooping on a device.
+func DisableIGMPSnooping(id string, options ...Option) ([]Device, error) {
+	return SetRecords(id, []Record{IGMPSnoopingVLAN(0).Record()}, options...)
+}
+
+// SetMulticastFilter enables or disables the filtering of unknown multicast
+// traffic. If enabled, multicast traffic is only forwarded to ports that
+// joined the multicast group, instead of being flooded to all ports.
+func SetMulticastFilter(id string, enabled bool, options ...Option) ([]Device, error) {
+	return SetRecords(id, []Record{NewBoolRecord(RecordMulticastFilter, enabled)}, options...)
+}
+
+// SetIGMPHeaderValidation enables or disables the validation of IGMPv3 headers.
+func SetIGMPHeaderValidation(id string, enabled bool, options ...Option) ([]Device, error) {
+	return SetRecords(id, []Record{NewBoolRecord(RecordIGMPHeaderValidation, enabled)}, options...)
+}
+
+// SetIGMPRouterPorts configures the static IGMP router ports of a device.
+// An empty slice of ports removes all static IGMP router ports.
+func SetIGMPRouterPorts(id string, ports []uint8, options ...Option) ([]Device, error) {
+	device, err := getDevice(id, []string{"mac", "portcount"}, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkPorts(ports, device.PortCount); err != nil {
+		return nil, err
+	}
+
+	return SetRecords(id, []Record{IGMPRouterPorts(ports).Record(device.PortCount)}, options...)
+}
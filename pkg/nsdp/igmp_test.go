@@ -0,0 +1,36 @@
+package nsdp
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestEnableIGMPSnoopingInvalidVLAN(t *testing.T) {
+	for _, vlan := range []uint16{0, VLANIDMax + 1} {
+		if _, err := EnableIGMPSnooping("all", vlan); !errors.Is(err, ErrInvalidVLANID) {
+			t.Errorf("VLAN %d: expected error %v, got %v", vlan, ErrInvalidVLANID, err)
+		}
+	}
+}
+
+func TestIGMPSnoopingVLANRecord(t *testing.T) {
+	tests := []struct {
+		vlan  IGMPSnoopingVLAN
+		value []byte
+	}{
+		{vlan: 0, value: []byte{0x00, 0x00, 0x00, 0x00}},
+		{vlan: 1, value: []byte{0x00, 0x01, 0x00, 0x01}},
+		{vlan: 4093, value: []byte{0x00, 0x01, 0x0F, 0xFD}},
+	}
+
+	for _, test := range tests {
+		record := test.vlan.Record()
+		if record.ID != RecordIGMPSnoopingVLAN.ID {
+			t.Errorf("VLAN %d: expected record %s, got %s", test.vlan, RecordIGMPSnoopingVLAN.ID, record.ID)
+		}
+		if !bytes.Equal(record.Value, test.value) {
+			t.Errorf("VLAN %d: expected value %X, got %X", test.vlan, test.value, record.Value)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package nsdp
+
+import (
+	"fmt"
+	"net"
+)
+
+// IPConfig describes the IP configuration of a device. If DHCP is
+// enabled, the static IP address, netmask and gateway are ignored.
+type IPConfig struct {
+	DHCP    bool
+	IP      net.IP
+	Netmask net.IP
+	Gateway net.IP
+}
+
+// Validate checks that the static IP address, netmask and gateway
+// are consistent, such that the device remains reachable.
+func (c IPConfig) Validate() error {
+	if c.DHCP {
+		return nil
+	}
+
+	ip := c.IP.To4()
+	if ip == nil {
+		return fmt.Errorf("%w: IP address must be an IPv4 address", ErrInvalidIPConfig)
+	}
+
+	netmask := c.Netmask.To4()
+	if netmask == nil {
+		return fmt.Errorf("%w: netmask must be an IPv4 address", ErrInvalidIPConfig)
+	}
+
+	mask := net.IPMask(netmask)
+	ones, bits := mask.Size()
+	if bits == 0 || ones == 0 || ones > 30 {
+		return fmt.Errorf("%w: netmask %s is not a valid netmask", ErrInvalidIPConfig, netmask)
+	}
+
+	if ip.IsUnspecified() || ip.IsMulticast() || ip.IsLoopback() {
+		return fmt.Errorf("%w: %s is not a valid host address", ErrInvalidIPConfig, ip)
+	}
+
+	network := ip.Mask(mask)
+	broadcast := make(net.IP, len(network))
+	for i := range network {
+		broadcast[i] = network[i] | ^mask[i]
+	}
+
+	if ip.Equal(network) || ip.Equal(broadcast) {
+		return fmt.Errorf("%w: %s is the network or broadcast address of %s/%d", ErrInvalidIPConfig, ip, network, ones)
+	}
+
+	// A gateway is optional, but if set, it must be reachable.
+	if c.Gateway == nil || c.Gateway.IsUnspecified() {
+		return nil
+	}
+
+	gateway := c.Gateway.To4()
+	if gateway == nil {
+		return fmt.Errorf("%w: gateway must be an IPv4 address", ErrInvalidIPConfig)
+	}
+
+	if !gateway.Mask(mask).Equal(network) {
+		return fmt.Errorf("%w: gateway %s is not in network %s/%d", ErrInvalidIPConfig, gateway, network, ones)
+	}
+
+	if gateway.Equal(ip) || gateway.Equal(network) || gateway.Equal(broadcast) {
+		return fmt.Errorf("%w: %s is not a valid gateway address", ErrInvalidIPConfig, gateway)
+	}
+
+	return nil
+}
+
+// Records encodes the IP configuration into records.
+func (c IPConfig) Records() []Record {
+	if c.DHCP {
+		return []Record{NewBoolRecord(RecordDHCP, true)}
+	}
+
+	gateway := net.IPv4zero.To4()
+	if c.Gateway != nil {
+		gateway = c.Gateway.To4()
+	}
+
+	return []Record{
+		NewRecord(RecordDHCP, []byte{0x00}),
+		NewRecord(RecordIP, c.IP.To4()),
+		NewRecord(RecordNetmask, c.Netmask.To4()),
+		NewRecord(RecordGateway, gateway),
+	}
+}
+
+// SetIPConfig validates the IP configuration and writes it to
+// a device in a single request. As the device may change its
+// IP address, it is recommended to identify it via its MAC
+// afterwards.
+func SetIPConfig(id string, config IPConfig, options ...Option) ([]Device, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	return SetRecords(id, config.Records(), options...)
+}
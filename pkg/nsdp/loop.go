@@ -0,0 +1,6 @@
+package nsdp
+
+// SetLoopDetection enables or disables the loop detection of a device.
+func SetLoopDetection(id string, enabled bool, options ...Option) ([]Device, error) {
+	return SetRecords(id, []Record{NewBoolRecord(RecordLoopDetection, enabled)}, options...)
+}
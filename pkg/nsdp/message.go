@@ -12,7 +12,10 @@ type Header struct {
 	Version   uint8
 	Operation OpCode
 	Result    uint16
-	_         [4]uint8
+	// Record contains the ID of the record that caused
+	// the operation to fail if the result is non-zero.
+	Record    RecordTypeID
+	_         [2]uint8
 	ClientMAC [6]uint8
 	ServerMAC [6]uint8
 	_         [2]uint8
@@ -0,0 +1,139 @@
+package nsdp
+
+import (
+	"bytes"
+	"testing"
+)
+
+// seedHeader is the header of a synthetic read response of a GS308E.
+// No captures of real devices are available, so the seeds are built
+// from records and use locally administered unicast MAC addresses.
+var seedHeader = Header{
+	Version:   1,
+	Operation: ReadResponse,
+	ClientMAC: [6]uint8{0x02, 0x00, 0x00, 0x00, 0x00, 0x42},
+	ServerMAC: [6]uint8{0x02, 0x00, 0x00, 0x00, 0x00, 0x01},
+	Sequence:  0xA1B2,
+	Signature: [4]uint8{'N', 'S', 'D', 'P'},
+}
+
+// seedRecords are the records of synthetic responses of a GS308E,
+// including the port mirroring values documented in Reflect and a
+// truncated record.
+var seedRecords = [][]Record{
+	{
+		NewRecord(RecordModel, []byte("GS308E")),
+		NewRecord(RecordName, []byte("switch-0")),
+		NewRecord(RecordMAC, []byte{0x02, 0x00, 0x00, 0x00, 0x00, 0x01}),
+		NewRecord(RecordIP, []byte{192, 168, 0, 253}),
+		NewRecord(RecordNetmask, []byte{255, 255, 255, 0}),
+		NewRecord(RecordGateway, []byte{192, 168, 0, 254}),
+		NewRecord(RecordDHCP, []byte{0x00}),
+		NewRecord(RecordFirmware, []byte("1.00.10")),
+		NewRecord(RecordPasswordEncryption, []byte{0x00, 0x00, 0x00, 0x10}),
+		NewRecord(RecordPortSpeeds, []byte{0x01, 0x05, 0x01}),
+		NewRecord(RecordPortSpeeds, []byte{0x02, 0x00, 0x01}),
+		NewRecord(RecordPortCount, []byte{0x08}),
+	},
+	{
+		NewRecord(RecordPortMetrics, append([]byte{0x01}, make([]byte, 48)...)),
+		NewRecord(RecordVLANEngine, []byte{uint8(VLANEngine802QAdvanced)}),
+		NewRecord(RecordVLAN802Q, []byte{0x00, 0x01, 0xC0, 0x3F}),
+		NewRecord(RecordVLAN802Q, []byte{0x00, 0x02, 0xC0, 0x00}),
+		NewRecord(RecordPVIDs, []byte{0x01, 0x00, 0x02}),
+		NewRecord(RecordPVIDs, []byte{0x03, 0x00, 0x01}),
+	},
+	{
+		NewRecord(RecordQoSEngine, []byte{uint8(QoSPort)}),
+		NewRecord(RecordQoSPolicies, []byte{0x01, uint8(QoSPriorityHigh)}),
+		NewRecord(RecordBandwidthLimitsIn, []byte{0x01, 0x00, 0x00, 0x00, uint8(BandwidthLimit256Mbps)}),
+		NewRecord(RecordBroadcastFilter, []byte{0x03}),
+		NewRecord(RecordPortMirroring, []byte{0x00, 0x00, 0x00}),
+		NewRecord(RecordPortMirroring, []byte{0x06, 0x00, 0x08}),
+		NewRecord(RecordPortMirroring, []byte{0x04, 0x00, 0x22}),
+		NewRecord(RecordPortMirroring, []byte{0x02, 0x00, 0x81}),
+		NewRecord(RecordIGMPSnoopingVLAN, []byte{0x00, 0x01, 0x00, 0x01}),
+		NewRecord(RecordMulticastFilter, []byte{0x03}),
+		NewRecord(RecordIGMPRouterPorts, []byte{0x80}),
+		NewRecord(RecordLoopDetection, []byte{0x03}),
+	},
+	{
+		NewRecord(RecordIP, []byte{192, 168}),
+		NewRecord(RecordPortMetrics, []byte{0x01, 0x00}),
+		{ID: RecordTypeID(0x7400), Len: 2, Value: []byte{0xAB, 0xCD}},
+	},
+}
+
+// fuzzSeeds returns the encoded seed messages.
+func fuzzSeeds(t testing.TB) [][]byte {
+	seeds := make([][]byte, 0, len(seedRecords))
+	for _, records := range seedRecords {
+		data, err := (&Message{Header: seedHeader, Records: records}).MarshalBinary()
+		if err != nil {
+			t.Fatalf("failed to encode seed: %v", err)
+		}
+		seeds = append(seeds, data)
+	}
+	return seeds
+}
+
+func FuzzMessageUnmarshalBinary(f *testing.F) {
+	for _, seed := range fuzzSeeds(f) {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		msg := new(Message)
+		if err := msg.UnmarshalBinary(data); err != nil {
+			return
+		}
+
+		// All records must be read from within the buffer.
+		size := 32
+		for _, record := range msg.Records {
+			if int(record.Len) != len(record.Value) {
+				t.Fatalf("record %s has length %d, but value of length %d", record.ID, record.Len, len(record.Value))
+			}
+			size += 4 + len(record.Value)
+		}
+		if size > len(data) {
+			t.Fatalf("decoded %d bytes from a buffer of %d bytes", size, len(data))
+		}
+
+		// Encoding the message again must result in the same records.
+		encoded, err := msg.MarshalBinary()
+		if err != nil {
+			t.Fatalf("failed to encode decoded message: %v", err)
+		}
+		decoded := new(Message)
+		if err := decoded.UnmarshalBinary(encoded); err != nil {
+			t.Fatalf("failed to decode encoded message: %v", err)
+		}
+		if len(decoded.Records) != len(msg.Records) {
+			t.Fatalf("expected %d records after round trip, got %d", len(msg.Records), len(decoded.Records))
+		}
+		for i, record := range decoded.Records {
+			if record.ID != msg.Records[i].ID || !bytes.Equal(record.Value, msg.Records[i].Value) {
+				t.Fatalf("record %d changed after round trip: %v != %v", i, record, msg.Records[i])
+			}
+		}
+	})
+}
+
+func TestMessageUnmarshalBinary(t *testing.T) {
+	for i, seed := range fuzzSeeds(t) {
+		msg := new(Message)
+		if err := msg.UnmarshalBinary(seed); err != nil {
+			t.Fatalf("seed %d: %v", i, err)
+		}
+		if msg.Header != seedHeader || len(msg.Records) != len(seedRecords[i]) {
+			t.Errorf("seed %d: expected %d records, got %d", i, len(seedRecords[i]), len(msg.Records))
+		}
+	}
+
+	// A record that is cut off must be rejected.
+	msg := new(Message)
+	if err := msg.UnmarshalBinary(fuzzSeeds(t)[0][:34]); err == nil {
+		t.Error("expected error for truncated record")
+	}
+}
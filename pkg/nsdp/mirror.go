@@ -0,0 +1,37 @@
+package nsdp
+
+import "fmt"
+
+// SetPortMirroring configures a device to mirror the traffic of the source
+// ports to the destination port. The destination port must not be a source
+// port and all ports must exist on the device.
+func SetPortMirroring(id string, mirroring PortMirroring, options ...Option) ([]Device, error) {
+	device, err := getDevice(id, []string{"mac", "portcount"}, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(mirroring.Sources) == 0 {
+		return nil, fmt.Errorf("%w: at least one source port is required", ErrInvalidPortMirroring)
+	}
+
+	if containsPort(mirroring.Sources, mirroring.Destination) {
+		return nil, fmt.Errorf("%w: destination port %d can't be a source port", ErrInvalidPortMirroring, mirroring.Destination)
+	}
+
+	if err := checkPorts(append([]uint8{mirroring.Destination}, mirroring.Sources...), device.PortCount); err != nil {
+		return nil, err
+	}
+
+	return SetRecords(id, []Record{mirroring.Record(device.PortCount)}, options...)
+}
+
+// DisablePortMirroring disables the port mirroring of a device.
+func DisablePortMirroring(id string, options ...Option) ([]Device, error) {
+	device, err := getDevice(id, []string{"mac", "portcount"}, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	return SetRecords(id, []Record{PortMirroring{}.Record(device.PortCount)}, options...)
+}
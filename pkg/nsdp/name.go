@@ -0,0 +1,32 @@
+package nsdp
+
+import "fmt"
+
+// NameMaxLen is the maximum length of a device name.
+const NameMaxLen = 20
+
+// ValidateName checks that a device name is not too long
+// and only contains printable ASCII characters, as the
+// devices do not support any other characters.
+func ValidateName(name string) error {
+	if len(name) > NameMaxLen {
+		return fmt.Errorf("%w: must not be longer than %d characters", ErrInvalidName, NameMaxLen)
+	}
+
+	for _, c := range name {
+		if c < 0x20 || c > 0x7E {
+			return fmt.Errorf("%w: character %q is not printable ASCII", ErrInvalidName, c)
+		}
+	}
+
+	return nil
+}
+
+// SetName validates the name and changes the name of a device.
+func SetName(id string, name string, options ...Option) ([]Device, error) {
+	if err := ValidateName(name); err != nil {
+		return nil, err
+	}
+
+	return SetRecords(id, []Record{NewRecord(RecordName, []byte(name))}, options...)
+}
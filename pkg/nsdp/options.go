@@ -2,8 +2,12 @@ package nsdp
 
 import (
 	"context"
+	"log/slog"
 	"net"
 	"time"
+
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
 )
 
 const (
@@ -36,12 +40,40 @@ func NewSelector() *Selector {
 	}
 }
 
+// ParseSelector creates a Selector from a device identifier, which may
+// either be a MAC address, an IP address or the keyword "all".
+func ParseSelector(id string) (*Selector, error) {
+	selector := NewSelector()
+	// Allow usage of keyword "all" to select all devices.
+	if id == "all" {
+		return selector, nil
+	}
+
+	// Check if the device is identified via its IP address.
+	ip := net.ParseIP(id)
+	if ip == nil {
+		// Fall back to MAC address device identification.
+		mac, err := net.ParseMAC(id)
+		if err != nil {
+			return nil, ErrInvalidDeviceIdentifier
+		}
+		return selector.SetMAC(&mac), nil
+	}
+
+	return selector.SetIP(&ip), nil
+}
+
 // SetMAC sets the MAC address of the selector and returns the selector.
 func (s *Selector) SetMAC(mac *net.HardwareAddr) *Selector {
 	s.MAC = mac
 	return s
 }
 
+// IsAll returns true if the selector matches all devices.
+func (s *Selector) IsAll() bool {
+	return s.MAC.String() == SelectorAll.MAC.String() && s.IP.Equal(*SelectorAll.IP)
+}
+
 // SetIP sets the IP address of the selector and returns the selector.
 func (s *Selector) SetIP(ip *net.IP) *Selector {
 	s.IP = ip
@@ -50,12 +82,18 @@ func (s *Selector) SetIP(ip *net.IP) *Selector {
 
 // Options defines the configuration of an operation of this library.
 type Options struct {
-	Context       context.Context
-	Selector      *Selector
-	InterfaceName string
-	Timeout       time.Duration
-	Retries       uint
-	Password      string
+	Context         context.Context
+	Selector        *Selector
+	InterfaceName   string
+	Timeout         time.Duration
+	AdaptiveTimeout bool
+	Retries         uint
+	RetryPolicy     *RetryPolicy
+	Warmup          bool
+	ResponseLimit   uint
+	Password        string
+	Logger          *slog.Logger
+	TracerProvider  trace.TracerProvider
 }
 
 // Apply applies the option functions to the current set of options.
@@ -75,9 +113,17 @@ type Option func(*Options) error
 // GetDefaultOptions returns the default options
 // for all operations of this library.
 func GetDefaultOptions() *Options {
+	// Copy the default retry policy such that it is not modified.
+	policy := DefaultRetryPolicy
+
 	return &Options{
-		Context:  context.Background(),
-		Selector: SelectorAll,
+		Context:     context.Background(),
+		Selector:    SelectorAll,
+		RetryPolicy: &policy,
+		Warmup:      true,
+		Logger:      slog.Default(),
+		// Tracing is disabled unless a tracer provider is supplied.
+		TracerProvider: noop.NewTracerProvider(),
 	}
 }
 
@@ -112,6 +158,16 @@ func WithTimeout(timeout time.Duration) Option {
 	}
 }
 
+// WithAdaptiveTimeout derives the timeout of every attempt from the
+// round-trip times measured via the cache configured with SetRTTCache.
+// The timeout supplied via WithTimeout is used for unknown devices.
+func WithAdaptiveTimeout(enabled bool) Option {
+	return func(o *Options) error {
+		o.AdaptiveTimeout = enabled
+		return nil
+	}
+}
+
 // WithRetries supplies the number of retries for the operation.
 func WithRetries(retries uint) Option {
 	return func(o *Options) error {
@@ -120,6 +176,42 @@ func WithRetries(retries uint) Option {
 	}
 }
 
+// WithRetryPolicy supplies the policy that defines the backoff between
+// the retries of the operation and when an attempt ends early.
+func WithRetryPolicy(policy *RetryPolicy) Option {
+	return func(o *Options) error {
+		if policy == nil {
+			return ErrInvalidRetryPolicy
+		}
+		if err := policy.Validate(); err != nil {
+			return err
+		}
+		o.RetryPolicy = policy
+		return nil
+	}
+}
+
+// WithWarmup controls whether a lightweight read is sent to prime the
+// ARP cache of the devices if they do not respond to the first attempt
+// of an operation. Scans are repeated once instead, as other devices may
+// have responded to the first attempt. The warm-up is enabled by default.
+func WithWarmup(enabled bool) Option {
+	return func(o *Options) error {
+		o.Warmup = enabled
+		return nil
+	}
+}
+
+// WithResponseLimit ends the operation as soon as the given number of
+// devices has responded instead of waiting for the full timeout. A
+// limit of zero waits for the full timeout.
+func WithResponseLimit(limit uint) Option {
+	return func(o *Options) error {
+		o.ResponseLimit = limit
+		return nil
+	}
+}
+
 // WithInterfaceName supplies the name of the network interface
 // to use for the operation.
 func WithInterfaceName(name string) Option {
@@ -136,3 +228,32 @@ func WithPassword(password string) Option {
 		return nil
 	}
 }
+
+// LevelTrace is the log level used to log the raw bytes of
+// all messages, which is more verbose than the debug level.
+const LevelTrace = slog.LevelDebug - 4
+
+// WithLogger supplies a logger for the operation. By default,
+// the default logger of the slog package is used. Pass a logger
+// with a level of LevelTrace to log the raw bytes of messages.
+func WithLogger(logger *slog.Logger) Option {
+	return func(o *Options) error {
+		if logger == nil {
+			return ErrInvalidLogger
+		}
+		o.Logger = logger
+		return nil
+	}
+}
+
+// WithTracerProvider supplies a tracer provider that is used to create
+// spans for the operation. By default, no spans are recorded.
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(o *Options) error {
+		if provider == nil {
+			return ErrInvalidTracerProvider
+		}
+		o.TracerProvider = provider
+		return nil
+	}
+}
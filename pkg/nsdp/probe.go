@@ -0,0 +1,230 @@
+package nsdp
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+)
+
+// ProbeBatchSize is the default number of record types read per request.
+// It is small enough to keep the responses within a single datagram.
+const ProbeBatchSize = 32
+
+// ProbeRecord describes a record that a device returned when probed.
+type ProbeRecord struct {
+	ID    RecordTypeID `json:"id"`
+	Name  string       `json:"name,omitempty"`
+	Len   uint16       `json:"len"`
+	Value string       `json:"value"`
+}
+
+// Profile describes the records that a device returns for a range
+// of record types. It can be shared to extend the record type table.
+type Profile struct {
+	Model    string        `json:"model"`
+	Firmware string        `json:"firmware"`
+	From     RecordTypeID  `json:"from"`
+	To       RecordTypeID  `json:"to"`
+	Records  []ProbeRecord `json:"records"`
+}
+
+// KnownRecordRange returns the range from the lowest to the highest
+// known record type, which is where devices are expected to respond.
+func KnownRecordRange() (RecordTypeID, RecordTypeID) {
+	from, to := RecordEndOfMessage.ID, RecordTypeID(0)
+	for id := range RecordTypeByID {
+		if id == RecordEndOfMessage.ID {
+			continue
+		}
+		if id < from {
+			from = id
+		}
+		if id > to {
+			to = id
+		}
+	}
+	return from, to
+}
+
+// Probe reads all record types in the given range from a single device
+// in batches and returns the records that the device responded with.
+// Action records are skipped, as their behavior on reads is unknown.
+// If a device rejects a batch, the record types of the batch are read
+// one by one, such that a single unsupported record type does not fail
+// the whole batch.
+func Probe(id string, from RecordTypeID, to RecordTypeID, batchSize int, options ...Option) (*Profile, error) {
+	opts, err := GetDefaultOptions().Apply(options...)
+	if err != nil {
+		return nil, err
+	}
+
+	if batchSize <= 0 {
+		batchSize = ProbeBatchSize
+	}
+
+	device, err := getDevice(id, []string{"model", "firmware", "mac"}, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	selector, err := ParseSelector(device.MAC.String())
+	if err != nil {
+		return nil, err
+	}
+
+	profile := &Profile{
+		Model:    device.Model,
+		Firmware: device.Firmware,
+		From:     from,
+		To:       to,
+		Records:  make([]ProbeRecord, 0),
+	}
+
+	for _, batch := range probeBatches(from, to, batchSize) {
+		records, err := probeBatch(opts, selector, batch)
+		if err != nil {
+			return nil, err
+		}
+		profile.Records = append(profile.Records, records...)
+	}
+
+	return profile, nil
+}
+
+// probeBatches splits the record types in the given range into batches
+// of the given size. Action records are skipped.
+func probeBatches(from RecordTypeID, to RecordTypeID, batchSize int) [][]RecordTypeID {
+	batches := make([][]RecordTypeID, 0)
+	batch := make([]RecordTypeID, 0, batchSize)
+	for rid := uint32(from); rid <= uint32(to); rid++ {
+		if rt := RecordTypeByID[RecordTypeID(rid)]; rt != nil && rt.Example == nil {
+			continue
+		}
+
+		batch = append(batch, RecordTypeID(rid))
+		if len(batch) == batchSize {
+			batches = append(batches, batch)
+			batch = make([]RecordTypeID, 0, batchSize)
+		}
+	}
+
+	// Send the remaining record types, even if the range ends on a skipped record type.
+	if len(batch) > 0 {
+		batches = append(batches, batch)
+	}
+
+	return batches
+}
+
+// probeBatch reads a batch of record types. If the device rejects the
+// batch, it falls back to reading the record types one by one.
+func probeBatch(opts *Options, selector *Selector, ids []RecordTypeID) ([]ProbeRecord, error) {
+	records, err := probeRead(opts, selector, ids)
+	if err == nil {
+		return records, nil
+	}
+
+	if errors.Is(err, ErrInvalidPassword) || errors.Is(err, ErrInvalidPasswordLockdown) || len(ids) == 1 {
+		return nil, err
+	}
+
+	records = make([]ProbeRecord, 0)
+	for _, id := range ids {
+		record, err := probeRead(opts, selector, []RecordTypeID{id})
+		if err != nil {
+			if errors.Is(err, ErrInvalidPassword) || errors.Is(err, ErrInvalidPasswordLockdown) {
+				return nil, err
+			}
+
+			// The device does not support the record type.
+			continue
+		}
+
+		records = append(records, record...)
+	}
+
+	return records, nil
+}
+
+// probeRead reads the record types and returns the records of the
+// response. The read is retried if the device does not respond and
+// a priming read is sent if it does not respond to the first attempt.
+func probeRead(opts *Options, selector *Selector, ids []RecordTypeID) ([]ProbeRecord, error) {
+	attempts := opts.Retries + 1
+	warmedUp := !opts.Warmup
+	for i := uint(0); i < attempts; i++ {
+		// Back off before retrying.
+		if delay := opts.RetryPolicy.Delay(i); delay > 0 {
+			if err := sleep(opts.Context, delay); err != nil {
+				return nil, err
+			}
+		}
+
+		responses, err := probeAttempt(opts, selector, ids, i+1)
+
+		// As the selector matches only the probed device, OperationErrors
+		// describe its failure and there are no responses of other devices.
+		if err != nil {
+			return nil, err
+		}
+
+		if len(responses) > 0 {
+			return probeRecords(responses[0]), nil
+		}
+
+		// Prime the ARP cache of the device and repeat the read if it responded.
+		if !warmedUp {
+			warmedUp = true
+
+			primed, err := warmup(opts.Context, opts, selector, 1)
+			if err != nil {
+				return nil, err
+			}
+			if primed {
+				attempts++
+			}
+		}
+	}
+
+	return nil, ErrNoDevicesFound
+}
+
+// probeAttempt sends a single read request to the probed device.
+func probeAttempt(opts *Options, selector *Selector, ids []RecordTypeID, attempt uint) ([]Message, error) {
+	request := NewMessage(ReadRequest)
+	for _, id := range ids {
+		request.Records = append(request.Records, Record{ID: id})
+	}
+
+	// Create context to handle timeout.
+	ctx, cancel := context.WithTimeout(opts.Context, opts.attemptTimeout(selector, attempt))
+	defer cancel()
+
+	return RequestMessages(opts.InterfaceName, request,
+		WithContext(ctx),
+		WithSelector(selector),
+		WithRetryPolicy(opts.RetryPolicy),
+		WithResponseLimit(1),
+		WithLogger(opts.Logger),
+		WithTracerProvider(opts.TracerProvider),
+	)
+}
+
+// probeRecords converts the records of a response into probed records.
+func probeRecords(response Message) []ProbeRecord {
+	records := make([]ProbeRecord, 0, len(response.Records))
+	for _, record := range response.Records {
+		probed := ProbeRecord{
+			ID:    record.ID,
+			Len:   record.Len,
+			Value: hex.EncodeToString(record.Value),
+		}
+		if rt := record.Type(); rt != nil {
+			probed.Name = rt.Name
+		}
+
+		records = append(records, probed)
+	}
+
+	return records
+}
@@ -0,0 +1,23 @@
+package nsdp
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestKnownRecordRange(t *testing.T) {
+	from, to := KnownRecordRange()
+	if from != RecordModel.ID || to != RecordLoopDetection.ID {
+		t.Errorf("expected range %s-%s, got %s-%s", RecordModel.ID, RecordLoopDetection.ID, from, to)
+	}
+}
+
+func TestProbeBatches(t *testing.T) {
+	// The range ends on the reboot action record, which is skipped.
+	batches := probeBatches(0x0010, RecordReboot.ID, 2)
+
+	want := [][]RecordTypeID{{0x0010, 0x0011}, {0x0012}}
+	if !reflect.DeepEqual(batches, want) {
+		t.Errorf("expected batches %v, got %v", want, batches)
+	}
+}
@@ -0,0 +1,33 @@
+package nsdp
+
+import "fmt"
+
+// SetQoSEngine changes the QoS engine of a device.
+func SetQoSEngine(id string, engine QoSEngine, options ...Option) ([]Device, error) {
+	return SetRecords(id, []Record{engine.Record()}, options...)
+}
+
+// SetQoSPriority assigns the priority to the given ports of a device.
+// As the priorities only take effect with the port-based QoS engine,
+// the operation is rejected if a different engine is active.
+func SetQoSPriority(id string, ports []uint8, priority QoSPriority, options ...Option) ([]Device, error) {
+	device, err := getDevice(id, []string{"mac", "portcount", "qosengine"}, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	if device.QoSEngine != QoSPort {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedQoSEngine, device.QoSEngine)
+	}
+
+	if err := checkPorts(ports, device.PortCount); err != nil {
+		return nil, err
+	}
+
+	records := make([]Record, len(ports))
+	for i, port := range ports {
+		records[i] = QoSPolicy{ID: port, Priority: priority}.Record()
+	}
+
+	return SetRecords(id, records, options...)
+}
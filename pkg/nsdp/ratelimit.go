@@ -0,0 +1,52 @@
+package nsdp
+
+// SetRateLimits changes the ingress and egress bandwidth limits of the given
+// ports of a device. If a limit is nil, the respective direction is not changed.
+func SetRateLimits(id string, ports []uint8, in *BandwidthLimit, out *BandwidthLimit, options ...Option) ([]Device, error) {
+	device, err := getDevice(id, []string{"mac", "portcount"}, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkPorts(ports, device.PortCount); err != nil {
+		return nil, err
+	}
+
+	records := make([]Record, 0, 2*len(ports))
+	for _, port := range ports {
+		if in != nil {
+			records = append(records, BandwidthPolicy{ID: port, Limit: *in}.Record(RecordBandwidthLimitsIn))
+		}
+		if out != nil {
+			records = append(records, BandwidthPolicy{ID: port, Limit: *out}.Record(RecordBandwidthLimitsOut))
+		}
+	}
+
+	return SetRecords(id, records, options...)
+}
+
+// SetStormControl changes the broadcast bandwidth limit of the given ports
+// of a device. The limits only take effect if the broadcast filter of the
+// device is enabled, which can be done via SetBroadcastFilter.
+func SetStormControl(id string, ports []uint8, limit BandwidthLimit, options ...Option) ([]Device, error) {
+	device, err := getDevice(id, []string{"mac", "portcount"}, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkPorts(ports, device.PortCount); err != nil {
+		return nil, err
+	}
+
+	records := make([]Record, len(ports))
+	for i, port := range ports {
+		records[i] = BandwidthPolicy{ID: port, Limit: limit}.Record(RecordBroadcastLimits)
+	}
+
+	return SetRecords(id, records, options...)
+}
+
+// SetBroadcastFilter enables or disables the broadcast storm control of a device.
+func SetBroadcastFilter(id string, enabled bool, options ...Option) ([]Device, error) {
+	return SetRecords(id, []Record{NewBoolRecord(RecordBroadcastFilter, enabled)}, options...)
+}
@@ -0,0 +1,18 @@
+package nsdp
+
+// actionTrigger is the value that must be written
+// to an action record to trigger the action.
+var actionTrigger = []byte{0x01}
+
+// Reboot reboots a device. The device will
+// not be reachable until it has restarted.
+func Reboot(id string, options ...Option) ([]Device, error) {
+	return SetRecords(id, []Record{NewRecord(RecordReboot, actionTrigger)}, options...)
+}
+
+// FactoryReset resets a device to its factory defaults. This also
+// resets the password and the IP configuration of the device, so
+// it is recommended to identify the device via its MAC afterwards.
+func FactoryReset(id string, options ...Option) ([]Device, error) {
+	return SetRecords(id, []Record{NewRecord(RecordFactoryReset, actionTrigger)}, options...)
+}
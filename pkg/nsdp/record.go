@@ -3,8 +3,10 @@ package nsdp
 import (
 	"encoding/binary"
 	"fmt"
+	"math"
 	"net"
 	"reflect"
+	"strconv"
 	"strings"
 )
 
@@ -50,6 +52,23 @@ func (l LinkStatus) String() string {
 	}
 }
 
+// Rate returns the speed of the link in bits per second.
+// If the link is down or unknown, it returns 0.
+func (l LinkStatus) Rate() uint64 {
+	switch l {
+	case LinkSpeed10MbitHalfDuplex, LinkSpeed10Mbit:
+		return 10e6
+	case LinkSpeed100MbitHalfDuplex, LinkSpeed100Mbit:
+		return 100e6
+	case LinkSpeed1Gbit:
+		return 1e9
+	case LinkSpeed10Gbit:
+		return 10e9
+	default:
+		return 0
+	}
+}
+
 // VLANEngine defines the VLAN engine.
 type VLANEngine uint8
 
@@ -84,6 +103,24 @@ func (e VLANEngine) String() string {
 	}
 }
 
+// Record encodes the VLAN engine into a record.
+func (e VLANEngine) Record() Record {
+	return NewRecord(RecordVLANEngine, []byte{uint8(e)})
+}
+
+// ParseVLANEngine parses the string representation of a VLAN engine.
+// The comparison is case-insensitive and ignores dots and dashes, so
+// both "802.1QBasic" and "802.1q-basic" are accepted.
+func ParseVLANEngine(s string) (VLANEngine, error) {
+	normalize := strings.NewReplacer(".", "", "-", "", "_", "")
+	for e := VLANEngineDisabled; e <= VLANEngine802QAdvanced; e++ {
+		if strings.EqualFold(normalize.Replace(s), normalize.Replace(e.String())) {
+			return e, nil
+		}
+	}
+	return VLANEngineDisabled, fmt.Errorf(`%w: "%s"`, ErrInvalidVLANEngine, s)
+}
+
 // QoSEngine defines the quality of service engine.
 type QoSEngine uint8
 
@@ -106,6 +143,21 @@ func (q QoSEngine) String() string {
 	}
 }
 
+// Record encodes the QoS engine into a record.
+func (q QoSEngine) Record() Record {
+	return NewRecord(RecordQoSEngine, []byte{uint8(q)})
+}
+
+// ParseQoSEngine parses the case-insensitive string representation of a QoS engine.
+func ParseQoSEngine(s string) (QoSEngine, error) {
+	for q := QoSPort; q <= QoSDSCP; q++ {
+		if strings.EqualFold(s, q.String()) {
+			return q, nil
+		}
+	}
+	return 0, fmt.Errorf(`%w: "%s"`, ErrInvalidQoSEngine, s)
+}
+
 // QoSPriority describes a port-based QoS priority.
 type QoSPriority uint8
 
@@ -136,6 +188,16 @@ func (p QoSPriority) String() string {
 	}
 }
 
+// ParseQoSPriority parses the case-insensitive string representation of a QoS priority.
+func ParseQoSPriority(s string) (QoSPriority, error) {
+	for p := QoSPriorityHigh; p <= QoSPriorityLow; p++ {
+		if strings.EqualFold(s, p.String()) {
+			return p, nil
+		}
+	}
+	return 0, fmt.Errorf(`%w: "%s"`, ErrInvalidQoSPriority, s)
+}
+
 // BandwidthLimit describes a bandwidth limit.
 type BandwidthLimit uint8
 
@@ -198,6 +260,69 @@ func (b BandwidthLimit) String() string {
 	}
 }
 
+// Rate returns the bandwidth limit in bits per second.
+// If no bandwidth limit is applied, it returns 0.
+func (b BandwidthLimit) Rate() uint64 {
+	switch {
+	case b == BandwidthLimitNone || b > BandwidthLimit512Mbps:
+		return 0
+	case b == BandwidthLimit512Kbps:
+		return 512e3
+	default:
+		// The bandwidth limits double with every
+		// step starting with 1Mbps as first step.
+		return 1e6 << (b - BandwidthLimit1Mbps)
+	}
+}
+
+// ParseBandwidthLimit parses a human-readable rate, such as "64Mbps",
+// "512k" or "1.5 Mbit/s", and snaps it to the nearest bandwidth limit
+// supported by the devices. The keywords "none" and "unlimited" as
+// well as a rate of 0 disable the bandwidth limit. Rates above the
+// largest limit of 512Mbps, such as "1g", disable the bandwidth limit
+// too, as the devices can't limit the bandwidth to these rates.
+func ParseBandwidthLimit(s string) (BandwidthLimit, error) {
+	rate := strings.ToLower(strings.ReplaceAll(s, " ", ""))
+	if rate == "none" || rate == "unlimited" {
+		return BandwidthLimitNone, nil
+	}
+
+	// Strip at most one unit suffix and one multiplier prefix of the unit.
+	for _, suffix := range []string{"bit/s", "b/s", "bps", "bit"} {
+		if r, ok := strings.CutSuffix(rate, suffix); ok {
+			rate = r
+			break
+		}
+	}
+	multiplier := 1.0
+	if r, ok := strings.CutSuffix(rate, "k"); ok {
+		rate, multiplier = r, 1e3
+	} else if r, ok := strings.CutSuffix(rate, "m"); ok {
+		rate, multiplier = r, 1e6
+	} else if r, ok := strings.CutSuffix(rate, "g"); ok {
+		rate, multiplier = r, 1e9
+	}
+
+	value, err := strconv.ParseFloat(rate, 64)
+	if err != nil || value < 0 || math.IsInf(value, 0) || math.IsNaN(value) {
+		return BandwidthLimitNone, fmt.Errorf(`%w: "%s"`, ErrInvalidBandwidthLimit, s)
+	}
+	if value == 0 || value*multiplier > float64(BandwidthLimit512Mbps.Rate()) {
+		return BandwidthLimitNone, nil
+	}
+
+	// The limits grow exponentially, so we compare
+	// them on a logarithmic scale to find the nearest.
+	nearest := BandwidthLimit512Kbps
+	for b := BandwidthLimit512Kbps; b <= BandwidthLimit512Mbps; b++ {
+		if math.Abs(math.Log2(value*multiplier/float64(b.Rate()))) < math.Abs(math.Log2(value*multiplier/float64(nearest.Rate()))) {
+			nearest = b
+		}
+	}
+
+	return nearest, nil
+}
+
 // EncryptionMode describes which encryption modes the switch supports.
 type EncryptionMode uint8
 
@@ -240,12 +365,16 @@ func (p PortSpeed) String() string {
 }
 
 // PortMetric contains network traffic metrics of a port.
-// TODO: Find out what the other metrics are.
+// All metrics are counters that are only reset if the
+// device reboots or if the counters are reset manually.
 type PortMetric struct {
-	ID              uint8
-	BytesReceived   uint64
-	BytesSent       uint64
-	ErrorsPacketCRC uint64
+	ID               uint8
+	BytesReceived    uint64
+	BytesSent        uint64
+	Packets          uint64
+	BroadcastPackets uint64
+	MulticastPackets uint64
+	ErrorsPacketCRC  uint64
 }
 
 // String returns the string representation of a port metric.
@@ -253,6 +382,33 @@ func (p PortMetric) String() string {
 	return fmt.Sprintf("%d:%d/%d/%d", p.ID, p.BytesReceived, p.BytesSent, p.ErrorsPacketCRC)
 }
 
+// Sub returns the difference of the counters of the port metric and
+// a previous sample of the same port. It takes care of counters that
+// were reset or wrapped around between the two samples.
+func (p PortMetric) Sub(previous PortMetric) PortMetric {
+	return PortMetric{
+		ID:               p.ID,
+		BytesReceived:    counterDelta(previous.BytesReceived, p.BytesReceived),
+		BytesSent:        counterDelta(previous.BytesSent, p.BytesSent),
+		Packets:          counterDelta(previous.Packets, p.Packets),
+		BroadcastPackets: counterDelta(previous.BroadcastPackets, p.BroadcastPackets),
+		MulticastPackets: counterDelta(previous.MulticastPackets, p.MulticastPackets),
+		ErrorsPacketCRC:  counterDelta(previous.ErrorsPacketCRC, p.ErrorsPacketCRC),
+	}
+}
+
+// counterDelta calculates the increase of a counter between two samples.
+// If the counter decreased, it either wrapped around or it was reset. A
+// wrap-around is assumed if the previous value was in the upper half of
+// the value range, as a reset counter will start counting from zero.
+func counterDelta(previous uint64, current uint64) uint64 {
+	if current >= previous || previous > math.MaxUint64/2 {
+		// Unsigned arithmetic takes care of the wrap-around.
+		return current - previous
+	}
+	return current
+}
+
 // PortMirroring describes the port mirroring configuration of all ports.
 type PortMirroring struct {
 	Destination uint8
@@ -267,6 +423,20 @@ func (p PortMirroring) String() string {
 	return fmt.Sprintf("%d:%s", p.Destination, joinInts(p.Sources, "+"))
 }
 
+// Record encodes the port mirroring configuration into a record for a
+// device with the given number of ports. The source bitmask always
+// contains at least two port groups, as this is what the devices
+// use even if they only have 8 ports.
+func (p PortMirroring) Record(portCount uint8) Record {
+	if portCount < 16 {
+		portCount = 16
+	}
+
+	value := append([]byte{p.Destination}, encodePortBitmask(p.Sources, portCount)...)
+
+	return NewRecord(RecordPortMirroring, value)
+}
+
 // VLANPort describes the configuration of a port-based VLAN.
 type VLANPort struct {
 	ID    uint16
@@ -278,6 +448,15 @@ func (v VLANPort) String() string {
 	return fmt.Sprintf("%d:%s", v.ID, joinInts(v.Ports, "+"))
 }
 
+// Record encodes the port-based VLAN into a record
+// for a device with the given number of ports.
+func (v VLANPort) Record(portCount uint8) Record {
+	value := binary.BigEndian.AppendUint16(nil, v.ID)
+	value = append(value, encodePortBitmask(v.Ports, portCount)...)
+
+	return NewRecord(RecordVLANPort, value)
+}
+
 // VLAN802Q describes the configuration of an 802.1Q VLAN.
 type VLAN802Q struct {
 	ID       uint16
@@ -290,6 +469,19 @@ func (v VLAN802Q) String() string {
 	return fmt.Sprintf("%dt%su%s", v.ID, joinInts(v.Tagged, "+"), joinInts(v.Untagged, "+"))
 }
 
+// Record encodes the 802.1Q VLAN into a record for a device with the given
+// number of ports. The record contains a bitmask of all member ports and
+// a bitmask of the tagged member ports.
+func (v VLAN802Q) Record(portCount uint8) Record {
+	members := append(append([]uint8{}, v.Untagged...), v.Tagged...)
+
+	value := binary.BigEndian.AppendUint16(nil, v.ID)
+	value = append(value, encodePortBitmask(members, portCount)...)
+	value = append(value, encodePortBitmask(v.Tagged, portCount)...)
+
+	return NewRecord(RecordVLAN802Q, value)
+}
+
 // PVID describes the PVID assignment of a port.
 type PVID struct {
 	ID   uint8
@@ -301,6 +493,11 @@ func (p PVID) String() string {
 	return fmt.Sprintf("%d:%d", p.ID, p.PVID)
 }
 
+// Record encodes the PVID mapping into a record.
+func (p PVID) Record() Record {
+	return NewRecord(RecordPVIDs, binary.BigEndian.AppendUint16([]byte{p.ID}, p.PVID))
+}
+
 // QoSPolicy describes the QoS policy of a port.
 type QoSPolicy struct {
 	ID       uint8
@@ -312,6 +509,11 @@ func (q QoSPolicy) String() string {
 	return fmt.Sprintf("%d:%s", q.ID, q.Priority.String())
 }
 
+// Record encodes the QoS policy into a record.
+func (q QoSPolicy) Record() Record {
+	return NewRecord(RecordQoSPolicies, []byte{q.ID, uint8(q.Priority)})
+}
+
 // BandwidthPolicy describes the bandwidth limit of a port.
 type BandwidthPolicy struct {
 	ID    uint8
@@ -323,22 +525,96 @@ func (b BandwidthPolicy) String() string {
 	return fmt.Sprintf("%d:%s", b.ID, b.Limit.String())
 }
 
+// Record encodes the bandwidth policy into a record of the given type,
+// as the same encoding is used for the ingress, egress and broadcast
+// bandwidth limits.
+func (b BandwidthPolicy) Record(rt *RecordType) Record {
+	return NewRecord(rt, []byte{b.ID, 0x00, 0x00, 0x00, uint8(b.Limit)})
+}
+
 // CableTestResult contains the results of a cable test.
 type CableTestResult []uint8
 
 // RecordTypeID is the ID of a RecordType.
 type RecordTypeID uint16
 
+// String returns the hexadecimal representation of the record type ID.
+func (id RecordTypeID) String() string {
+	return fmt.Sprintf("0x%04X", uint16(id))
+}
+
+// MarshalText encodes the record type ID in its hexadecimal representation.
+func (id RecordTypeID) MarshalText() ([]byte, error) {
+	return []byte(id.String()), nil
+}
+
+// UnmarshalText decodes a record type ID, which may either be
+// in its hexadecimal or in its decimal representation.
+func (id *RecordTypeID) UnmarshalText(text []byte) error {
+	value, err := strconv.ParseUint(string(text), 0, 16)
+	if err != nil {
+		return fmt.Errorf("invalid record type ID: %s", text)
+	}
+
+	*id = RecordTypeID(value)
+	return nil
+}
+
 // IGMPSnoopingVLAN describes the VLAN ID of the IGMP
 // snooping VLAN. If this value is zero, it is disabled.
 type IGMPSnoopingVLAN uint16
 
+// String returns the string representation of the IGMP snooping VLAN.
+func (v IGMPSnoopingVLAN) String() string {
+	if v == 0 {
+		return "Disabled"
+	}
+	return fmt.Sprint(uint16(v))
+}
+
+// Record encodes the IGMP snooping VLAN into a record. The first two bytes
+// indicate whether IGMP snooping is enabled and the last two bytes contain
+// the VLAN ID.
+func (v IGMPSnoopingVLAN) Record() Record {
+	enabled := uint16(0x0000)
+	if v != 0 {
+		enabled = 0x0001
+	}
+
+	value := binary.BigEndian.AppendUint16(nil, enabled)
+	value = binary.BigEndian.AppendUint16(value, uint16(v))
+
+	return NewRecord(RecordIGMPSnoopingVLAN, value)
+}
+
+// IGMPRouterPorts describes the ports that are statically configured as
+// IGMP router ports. Multicast reports are always forwarded to them.
+type IGMPRouterPorts []uint8
+
+// String returns the string representation of the IGMP router ports.
+func (p IGMPRouterPorts) String() string {
+	if len(p) == 0 {
+		return "None"
+	}
+	return joinInts(p, "+")
+}
+
+// Record encodes the IGMP router ports into a record
+// for a device with the given number of ports.
+func (p IGMPRouterPorts) Record(portCount uint8) Record {
+	return NewRecord(RecordIGMPRouterPorts, encodePortBitmask(p, portCount))
+}
+
 // RecordType describes which data a Record contains.
 type RecordType struct {
 	ID      RecordTypeID
 	Name    string
 	Example interface{}
 	Slice   bool
+	// EnabledValue is the value that a boolean record is written with to
+	// enable a feature. It differs between records, so it is set for
+	// every boolean record. Any non-zero value is read as enabled.
+	EnabledValue byte
 }
 
 // NewRecordType creates a new record type.
@@ -356,6 +632,12 @@ func (r *RecordType) SetSlice(slice bool) *RecordType {
 	return r
 }
 
+// SetEnabledValue sets the value that enables the feature of a boolean record.
+func (r *RecordType) SetEnabledValue(value byte) *RecordType {
+	r.EnabledValue = value
+	return r
+}
+
 // TODO: Define interface for record type that allows encoding and decoding into more semantic structs.
 // TODO: Add missing records types once all operations are implemented.
 
@@ -374,20 +656,26 @@ var (
 	RecordGateway = NewRecordType(0x0008, "Gateway", net.IP{192, 168, 0, 254})
 	// RecordPassword contains the device's password and must be specified for write requests.
 	RecordPassword = NewRecordType(0x000A, "Password", "password")
-	// RecordDHCP contains the device's DHCP status.
-	RecordDHCP = NewRecordType(0x000B, "DHCP", false)
+	// RecordDHCP contains the device's DHCP status. Writing 0x01 enables
+	// DHCP, while 0x02 would only renew the lease, hence it can't use 0x03.
+	RecordDHCP = NewRecordType(0x000B, "DHCP", false).SetEnabledValue(0x01)
 	// RecordFirmware contains the device's firmware version.
 	RecordFirmware = NewRecordType(0x000D, "Firmware", "1.00.10")
+	// RecordReboot triggers a reboot of the device when written. Like all
+	// action records it is write-only and not exposed as configuration key.
+	RecordReboot = NewRecordType(0x0013, "Reboot", nil)
 	// RecordPasswordEncryption specifies which encryption methods the switch supports.
 	RecordPasswordEncryption = NewRecordType(0x0014, "PasswordEncryption", EncryptionModeHash64)
 	// RecordPasswordNonce contains the device's encryption nonce.
 	RecordPasswordNonce = NewRecordType(0x0017, "PasswordNonce", []byte{0x01, 0x02, 0x03, 0x04})
 	// RecordPasswordHash specifies a hashed password for authentication.
 	RecordPasswordHash = NewRecordType(0x001A, "PasswordHash", []byte{0x01, 0x02, 0x03, 0x04})
+	// RecordFactoryReset resets the device to its factory defaults when written.
+	RecordFactoryReset = NewRecordType(0x0400, "FactoryReset", nil)
 	// RecordPortSpeeds contains the link status and the speed of a port.
 	RecordPortSpeeds = NewRecordType(0x0C00, "PortSpeeds", []PortSpeed{{1, LinkSpeed1Gbit}, {2, LinkDown}}).SetSlice(true)
 	// RecordPortMetrics contains network traffic metrics of a port.
-	RecordPortMetrics = NewRecordType(0x1000, "PortMetrics", []PortMetric{{1, 64, 32, 0}}).SetSlice(true)
+	RecordPortMetrics = NewRecordType(0x1000, "PortMetrics", []PortMetric{{ID: 1, BytesReceived: 64, BytesSent: 32}}).SetSlice(true)
 	// RecordCableTestResult contains the result of a cable test.
 	RecordCableTestResult = NewRecordType(0x1C00, "CableTestResult", CableTestResult{0, 0, 0, 0, 0, 119, 30, 183, 118})
 	// RecordVLANEngine contains the active VLAN engine.
@@ -396,6 +684,8 @@ var (
 	RecordVLANPort = NewRecordType(0x2400, "VLANsPort", []VLANPort{{1, []uint8{1, 2, 3, 4, 5, 6, 7, 8}}}).SetSlice(true)
 	// RecordVLAN802Q contains the configuration of a 802.1Q VLAN.
 	RecordVLAN802Q = NewRecordType(0x2800, "VLANs802Q", []VLAN802Q{{1, []uint8{1, 2}, []uint8{3, 4, 5, 6, 7, 8}}}).SetSlice(true)
+	// RecordVLANDelete deletes the VLAN with the given ID when written.
+	RecordVLANDelete = NewRecordType(0x2C00, "VLANDelete", nil)
 	// RecordPVIDs contains the 802.1Q VLAN IDs for each port often also referred to as PVIDs.
 	RecordPVIDs = NewRecordType(0x3000, "PVIDs", []PVID{{1, 2}, {2, 2}, {3, 1}, {4, 1}, {5, 1}, {6, 1}, {7, 1}, {8, 1}}).SetSlice(true)
 	// RecordQoSEngine contains the QoS engine.
@@ -407,7 +697,8 @@ var (
 	// RecordBandwidthLimitsOut contains the inbound bandwidth limit of a port.
 	RecordBandwidthLimitsOut = NewRecordType(0x5000, "BandwidthLimitsOut", []BandwidthPolicy{{1, BandwidthLimit256Mbps}, {2, BandwidthLimitNone}}).SetSlice(true)
 	// RecordBroadcastFilter defines whether broadcast storm control is enabled.
-	RecordBroadcastFilter = NewRecordType(0x5400, "BroadcastFilter", false)
+	// Storm control is enabled with 0x03, which is also the value it is reported with.
+	RecordBroadcastFilter = NewRecordType(0x5400, "BroadcastFilter", false).SetEnabledValue(0x03)
 	// RecordBroadcastLimits contains the broadcast filter configuration of a port.
 	RecordBroadcastLimits = NewRecordType(0x5800, "BroadcastLimits", []BandwidthPolicy{{1, BandwidthLimit256Mbps}, {2, BandwidthLimitNone}}).SetSlice(true)
 	// RecordPortMirroring contains the mirroring configuration of all ports.
@@ -417,11 +708,16 @@ var (
 	// RecordIGMPSnoopingVLAN contains the VLAN ID used for IGMP snooping.
 	RecordIGMPSnoopingVLAN = NewRecordType(0x6800, "IGMPSnoopingVLAN", IGMPSnoopingVLAN(1))
 	// RecordMulticastFilter defines whether the device is configured to filter unknown multicast addresses.
-	RecordMulticastFilter = NewRecordType(0x6C00, "MulticastFilter", false)
+	// Like the broadcast filter, blocking unknown multicast addresses is enabled with 0x03.
+	RecordMulticastFilter = NewRecordType(0x6C00, "MulticastFilter", false).SetEnabledValue(0x03)
 	// RecordIGMPHeaderValidation contains the IGMPv3 header validation status of the device.
-	RecordIGMPHeaderValidation = NewRecordType(0x7000, "IGMPHeaderValidation", false)
+	// It is a plain flag that is enabled with 0x01, like the flag of RecordIGMPSnoopingVLAN.
+	RecordIGMPHeaderValidation = NewRecordType(0x7000, "IGMPHeaderValidation", false).SetEnabledValue(0x01)
+	// RecordIGMPRouterPorts contains the static IGMP router ports of the device.
+	RecordIGMPRouterPorts = NewRecordType(0x8000, "IGMPRouterPorts", IGMPRouterPorts{1})
 	// RecordLoopDetection contains the loop detection status of the device.
-	RecordLoopDetection = NewRecordType(0x9000, "LoopDetection", false)
+	// Loop detection is enabled with 0x03, which is also the value it is reported with.
+	RecordLoopDetection = NewRecordType(0x9000, "LoopDetection", false).SetEnabledValue(0x03)
 	// RecordEndOfMessage special record type that identifies the end
 	// of the message. Combined with a length of 0, this forms the 4
 	// magic bytes that mark the end of the message (0xFFFF0000).
@@ -439,15 +735,18 @@ var RecordTypeByID = map[RecordTypeID]*RecordType{
 	RecordPassword.ID:             RecordPassword,
 	RecordDHCP.ID:                 RecordDHCP,
 	RecordFirmware.ID:             RecordFirmware,
+	RecordReboot.ID:               RecordReboot,
 	RecordPasswordEncryption.ID:   RecordPasswordEncryption,
 	RecordPasswordNonce.ID:        RecordPasswordNonce,
 	RecordPasswordHash.ID:         RecordPasswordHash,
+	RecordFactoryReset.ID:         RecordFactoryReset,
 	RecordPortSpeeds.ID:           RecordPortSpeeds,
 	RecordPortMetrics.ID:          RecordPortMetrics,
 	RecordCableTestResult.ID:      RecordCableTestResult,
 	RecordVLANEngine.ID:           RecordVLANEngine,
 	RecordVLANPort.ID:             RecordVLANPort,
 	RecordVLAN802Q.ID:             RecordVLAN802Q,
+	RecordVLANDelete.ID:           RecordVLANDelete,
 	RecordPVIDs.ID:                RecordPVIDs,
 	RecordQoSEngine.ID:            RecordQoSEngine,
 	RecordQoSPolicies.ID:          RecordQoSPolicies,
@@ -460,6 +759,7 @@ var RecordTypeByID = map[RecordTypeID]*RecordType{
 	RecordIGMPSnoopingVLAN.ID:     RecordIGMPSnoopingVLAN,
 	RecordMulticastFilter.ID:      RecordMulticastFilter,
 	RecordIGMPHeaderValidation.ID: RecordIGMPHeaderValidation,
+	RecordIGMPRouterPorts.ID:      RecordIGMPRouterPorts,
 	RecordLoopDetection.ID:        RecordLoopDetection,
 	RecordEndOfMessage.ID:         RecordEndOfMessage,
 }
@@ -472,7 +772,7 @@ func indexRecordTypeNames() map[string]*RecordType {
 	recordNames := make(map[string]*RecordType, len(RecordTypeByID))
 
 	for _, record := range RecordTypeByID {
-		// Exclude the None and the EndOfMessage record types.
+		// Exclude action records and the EndOfMessage record type.
 		if record.Example != nil {
 			recordNames[strings.ToLower(record.Name)] = record
 		}
@@ -499,6 +799,22 @@ const (
 	WriteResponse
 )
 
+// String returns the name of the operation.
+func (o OpCode) String() string {
+	switch o {
+	case ReadRequest:
+		return "ReadRequest"
+	case ReadResponse:
+		return "ReadResponse"
+	case WriteRequest:
+		return "WriteRequest"
+	case WriteResponse:
+		return "WriteResponse"
+	default:
+		return fmt.Sprintf("Unknown(0x%02X)", uint8(o))
+	}
+}
+
 // Record defines the binary encoding of a
 // type-length-value object, which makes it
 // possible to encode variable length values
@@ -509,41 +825,72 @@ type Record struct {
 	Value []uint8
 }
 
+// NewBoolRecord creates a new record of the given type with a boolean
+// value. A feature is enabled with the EnabledValue of the record type.
+func NewBoolRecord(rt *RecordType, enabled bool) Record {
+	if enabled {
+		return NewRecord(rt, []byte{rt.EnabledValue})
+	}
+	return NewRecord(rt, []byte{0x00})
+}
+
+// NewRecord creates a new record of the given type with the given value.
+func NewRecord(rt *RecordType, value []byte) Record {
+	return Record{
+		ID:    rt.ID,
+		Len:   uint16(len(value)),
+		Value: value,
+	}
+}
+
 // Type returns the type of the record.
 func (r Record) Type() *RecordType {
 	return RecordTypeByID[r.ID]
 }
 
-// Reflect returns a reflect.Value of the record's value.
-func (r Record) Reflect() reflect.Value {
+// Reflect returns a reflect.Value of the record's value. It returns
+// a DecodeError if the value is too short for the record type.
+func (r Record) Reflect() (reflect.Value, error) {
 	rt := r.Type()
 	if rt == nil {
-		return reflect.ValueOf((*byte)(nil))
+		return reflect.ValueOf((*byte)(nil)), nil
+	}
+
+	// Ensure that the decoders below never read beyond the value.
+	if minLen := decodeMinLen(rt.Example); len(r.Value) < minLen {
+		return reflect.Value{}, &DecodeError{
+			RecordType: rt,
+			Len:        uint16(len(r.Value)),
+			Offset:     minLen,
+		}
 	}
 
 	switch rt.Example.(type) {
 	case string:
-		return reflect.ValueOf(string(r.Value))
+		return reflect.ValueOf(string(r.Value)), nil
 	case uint8:
-		return reflect.ValueOf(uint8(r.Value[0]))
+		return reflect.ValueOf(uint8(r.Value[0])), nil
 	case bool:
-		return reflect.ValueOf(bool(r.Value[0] > 0))
+		return reflect.ValueOf(bool(r.Value[0] > 0)), nil
 	case net.HardwareAddr:
-		return reflect.ValueOf(net.HardwareAddr(r.Value))
+		return reflect.ValueOf(net.HardwareAddr(r.Value)), nil
 	case net.IP:
-		return reflect.ValueOf(net.IP(r.Value))
+		return reflect.ValueOf(net.IP(r.Value)), nil
 	case []PortSpeed:
 		return reflect.ValueOf(PortSpeed{
 			ID:    r.Value[0],
 			Speed: LinkStatus(r.Value[1]),
-		})
+		}), nil
 	case []PortMetric:
 		return reflect.ValueOf(PortMetric{
-			ID:              r.Value[0],
-			BytesReceived:   binary.BigEndian.Uint64(r.Value[1:9]),
-			BytesSent:       binary.BigEndian.Uint64(r.Value[9:17]),
-			ErrorsPacketCRC: binary.BigEndian.Uint64(r.Value[41:49]),
-		})
+			ID:               r.Value[0],
+			BytesReceived:    binary.BigEndian.Uint64(r.Value[1:9]),
+			BytesSent:        binary.BigEndian.Uint64(r.Value[9:17]),
+			Packets:          binary.BigEndian.Uint64(r.Value[17:25]),
+			BroadcastPackets: binary.BigEndian.Uint64(r.Value[25:33]),
+			MulticastPackets: binary.BigEndian.Uint64(r.Value[33:41]),
+			ErrorsPacketCRC:  binary.BigEndian.Uint64(r.Value[41:49]),
+		}), nil
 	case PortMirroring:
 		// I can for sure make out that uint8[0] is the destination
 		// port. The other bits seem to be a bitmask. On my 8-port
@@ -563,60 +910,89 @@ func (r Record) Reflect() reflect.Value {
 		return reflect.ValueOf(PortMirroring{
 			Destination: r.Value[0],
 			Sources:     decodePortBitmask(r.Value[1:]),
-		})
+		}), nil
 	case IGMPSnoopingVLAN:
 		// If the value is 1, the IGMP snooping is enabled.
 		if binary.BigEndian.Uint16(r.Value[0:2]) == 0x0001 {
-			return reflect.ValueOf(IGMPSnoopingVLAN(binary.BigEndian.Uint16(r.Value[2:4])))
+			return reflect.ValueOf(IGMPSnoopingVLAN(binary.BigEndian.Uint16(r.Value[2:4]))), nil
 		}
-		return reflect.ValueOf(IGMPSnoopingVLAN(0))
+		return reflect.ValueOf(IGMPSnoopingVLAN(0)), nil
+	case IGMPRouterPorts:
+		return reflect.ValueOf(IGMPRouterPorts(decodePortBitmask(r.Value))), nil
 	case VLANEngine:
-		return reflect.ValueOf(VLANEngine(r.Value[0]))
+		return reflect.ValueOf(VLANEngine(r.Value[0])), nil
 	case []VLANPort:
 		return reflect.ValueOf(VLANPort{
 			ID:    binary.BigEndian.Uint16(r.Value[0:2]),
 			Ports: decodePortBitmask(r.Value[2:]),
-		})
+		}), nil
 	case []VLAN802Q:
-		portGroups := (r.Len - 2) / 2
+		// The first bitmask contains all member ports of the VLAN
+		// and the second bitmask contains the tagged member ports.
+		// The untagged ports are therefore all members not tagged.
+		portGroups := (len(r.Value) - 2) / 2
+		tagged := decodePortBitmask(r.Value[2+portGroups:])
 		return reflect.ValueOf(VLAN802Q{
 			ID:       binary.BigEndian.Uint16(r.Value[0:2]),
-			Untagged: decodePortBitmask(r.Value[2 : 2+portGroups]),
-			Tagged:   decodePortBitmask(r.Value[2+portGroups:]),
-		})
+			Untagged: excludePorts(decodePortBitmask(r.Value[2:2+portGroups]), tagged),
+			Tagged:   tagged,
+		}), nil
 	case []PVID:
 		return reflect.ValueOf(PVID{
 			ID:   r.Value[0],
 			PVID: binary.BigEndian.Uint16(r.Value[1:3]),
-		})
+		}), nil
 	case QoSEngine:
-		return reflect.ValueOf(QoSEngine(r.Value[0]))
+		return reflect.ValueOf(QoSEngine(r.Value[0])), nil
 	case []QoSPolicy:
 		return reflect.ValueOf(QoSPolicy{
 			ID:       r.Value[0],
 			Priority: QoSPriority(r.Value[1]),
-		})
+		}), nil
 	case []BandwidthPolicy:
 		return reflect.ValueOf(BandwidthPolicy{
 			ID:    r.Value[0],
 			Limit: BandwidthLimit(r.Value[4]),
-		})
+		}), nil
 	case EncryptionMode:
-		switch r.Value[r.Len-1] {
+		switch r.Value[len(r.Value)-1] {
 		case 0x00:
-			return reflect.ValueOf(EncryptionModeNone)
+			return reflect.ValueOf(EncryptionModeNone), nil
 		case 0x01:
-			return reflect.ValueOf(EncryptionModeSimple)
+			return reflect.ValueOf(EncryptionModeSimple), nil
 		case 0x08:
-			return reflect.ValueOf(EncryptionModeHash32)
+			return reflect.ValueOf(EncryptionModeHash32), nil
 		case 0x10:
-			return reflect.ValueOf(EncryptionModeHash64)
+			return reflect.ValueOf(EncryptionModeHash64), nil
 		default:
-			return reflect.ValueOf(EncryptionModeNone)
+			return reflect.ValueOf(EncryptionModeNone), nil
 		}
 	default:
 		// TODO: Parse CableTestResult.
-		return reflect.ValueOf(r.Value)
+		return reflect.ValueOf(r.Value), nil
+	}
+}
+
+// decodeMinLen returns the minimum length of a record value
+// that is required to decode it into the type of the example.
+func decodeMinLen(example interface{}) int {
+	switch example.(type) {
+	case uint8, bool, VLANEngine, QoSEngine, EncryptionMode, PortMirroring:
+		return 1
+	case []PortSpeed, []VLANPort, []VLAN802Q, []QoSPolicy:
+		return 2
+	case []PVID:
+		return 3
+	case net.IP, IGMPSnoopingVLAN:
+		return 4
+	case []BandwidthPolicy:
+		return 5
+	case net.HardwareAddr:
+		return 6
+	case []PortMetric:
+		return 49
+	default:
+		return 0
 	}
 }
 
@@ -652,6 +1028,49 @@ func decodePortBitmask(portGroups []uint8) []uint8 {
 	return ports
 }
 
+// encodePortBitmask takes a slice of ports and returns a bitmask, which
+// uses the same layout as the bitmask consumed by decodePortBitmask.
+func encodePortBitmask(ports []uint8, portCount uint8) []uint8 {
+	portGroupSize := 8
+	portGroups := make([]uint8, (int(portCount)+portGroupSize-1)/portGroupSize)
+	for _, port := range ports {
+		// Ports outside of the bitmask can't be encoded.
+		if port == 0 || int(port) > len(portGroups)*portGroupSize {
+			continue
+		}
+
+		// The port group containing the highest port
+		// number is mapped to the first byte, while
+		// the most significant bit of each byte maps
+		// to the lowest port number of the group.
+		pg := len(portGroups) - 1 - (int(port)-1)/portGroupSize
+		bit := portGroupSize - 1 - (int(port)-1)%portGroupSize
+		portGroups[pg] |= 1 << bit
+	}
+	return portGroups
+}
+
+// excludePorts returns all ports that are not contained in the exclusions.
+func excludePorts(ports []uint8, exclusions []uint8) []uint8 {
+	remaining := make([]uint8, 0, len(ports))
+	for _, port := range ports {
+		if !containsPort(exclusions, port) {
+			remaining = append(remaining, port)
+		}
+	}
+	return remaining
+}
+
+// containsPort checks if the port is contained in the slice of ports.
+func containsPort(ports []uint8, port uint8) bool {
+	for _, p := range ports {
+		if p == port {
+			return true
+		}
+	}
+	return false
+}
+
 // joinInts converts a slice of integers to a string.
 func joinInts(ints []uint8, delimiter string) string {
 	return strings.Trim(strings.ReplaceAll(fmt.Sprint(ints), " ", delimiter), "[]")
@@ -0,0 +1,122 @@
+package nsdp
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestNewBoolRecord(t *testing.T) {
+	tests := []struct {
+		rt      *RecordType
+		enabled bool
+		value   []byte
+	}{
+		{rt: RecordDHCP, enabled: true, value: []byte{0x01}},
+		{rt: RecordDHCP, enabled: false, value: []byte{0x00}},
+		{rt: RecordBroadcastFilter, enabled: true, value: []byte{0x03}},
+		{rt: RecordMulticastFilter, enabled: true, value: []byte{0x03}},
+		{rt: RecordIGMPHeaderValidation, enabled: true, value: []byte{0x01}},
+		{rt: RecordLoopDetection, enabled: true, value: []byte{0x03}},
+		{rt: RecordLoopDetection, enabled: false, value: []byte{0x00}},
+	}
+
+	for _, test := range tests {
+		record := NewBoolRecord(test.rt, test.enabled)
+		if record.ID != test.rt.ID || record.Len != 1 || !bytes.Equal(record.Value, test.value) {
+			t.Errorf("NewBoolRecord(%s, %t) = %v, want value %v", test.rt.Name, test.enabled, record, test.value)
+		}
+	}
+}
+
+func TestBoolRecordsHaveEnabledValue(t *testing.T) {
+	for _, rt := range RecordTypeByID {
+		if _, ok := rt.Example.(bool); ok && rt.EnabledValue == 0 {
+			t.Errorf("boolean record %s has no enabled value", rt.Name)
+		}
+	}
+}
+
+func TestPortBitmask(t *testing.T) {
+	tests := []struct {
+		name      string
+		ports     []uint8
+		portCount uint8
+		bitmask   []uint8
+	}{
+		{name: "no ports", ports: []uint8{}, portCount: 8, bitmask: []uint8{0x00}},
+		{name: "first port", ports: []uint8{1}, portCount: 8, bitmask: []uint8{0x80}},
+		{name: "last port", ports: []uint8{8}, portCount: 8, bitmask: []uint8{0x01}},
+		{name: "all ports", ports: []uint8{1, 2, 3, 4, 5, 6, 7, 8}, portCount: 8, bitmask: []uint8{0xFF}},
+		{name: "five ports", ports: []uint8{1, 5}, portCount: 5, bitmask: []uint8{0x88}},
+		{name: "second port group", ports: []uint8{1, 9, 16}, portCount: 16, bitmask: []uint8{0x81, 0x80}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			bitmask := encodePortBitmask(test.ports, test.portCount)
+			if !bytes.Equal(bitmask, test.bitmask) {
+				t.Fatalf("expected bitmask %v, got %v", test.bitmask, bitmask)
+			}
+
+			ports := sortPorts(decodePortBitmask(bitmask))
+			if !bytes.Equal(ports, test.ports) {
+				t.Fatalf("expected ports %v, got %v", test.ports, ports)
+			}
+		})
+	}
+}
+
+func TestEncodePortBitmaskSkipsInvalidPorts(t *testing.T) {
+	bitmask := encodePortBitmask([]uint8{0, 2, 9}, 8)
+	if !bytes.Equal(bitmask, []uint8{0x40}) {
+		t.Errorf("expected bitmask [64], got %v", bitmask)
+	}
+}
+
+func TestDecodePortBitmaskMirroring(t *testing.T) {
+	// The source ports of the port mirroring examples documented in Reflect.
+	tests := []struct {
+		bitmask []uint8
+		ports   []uint8
+	}{
+		{bitmask: []uint8{0, 0}, ports: []uint8{}},
+		{bitmask: []uint8{0, 8}, ports: []uint8{5}},
+		{bitmask: []uint8{0, 34}, ports: []uint8{3, 7}},
+		{bitmask: []uint8{0, 129}, ports: []uint8{1, 8}},
+	}
+
+	for _, test := range tests {
+		if ports := decodePortBitmask(test.bitmask); !bytes.Equal(ports, test.ports) {
+			t.Errorf("decodePortBitmask(%v) = %v, want %v", test.bitmask, ports, test.ports)
+		}
+	}
+}
+
+func TestParseBandwidthLimit(t *testing.T) {
+	tests := []struct {
+		rate  string
+		limit BandwidthLimit
+		err   error
+	}{
+		{rate: "64Mbps", limit: BandwidthLimit64Mbps},
+		{rate: "512k", limit: BandwidthLimit512Kbps},
+		{rate: "1.5 Mbit/s", limit: BandwidthLimit2Mbps},
+		{rate: "512m", limit: BandwidthLimit512Mbps},
+		{rate: "1g", limit: BandwidthLimitNone},
+		{rate: "0", limit: BandwidthLimitNone},
+		{rate: "unlimited", limit: BandwidthLimitNone},
+		{rate: "64mk", err: ErrInvalidBandwidthLimit},
+		{rate: "1gm", err: ErrInvalidBandwidthLimit},
+		{rate: "64bpsbit", err: ErrInvalidBandwidthLimit},
+		{rate: "-1m", err: ErrInvalidBandwidthLimit},
+		{rate: "inf", err: ErrInvalidBandwidthLimit},
+	}
+
+	for _, test := range tests {
+		limit, err := ParseBandwidthLimit(test.rate)
+		if !errors.Is(err, test.err) || limit != test.limit {
+			t.Errorf("ParseBandwidthLimit(%q) = %s, %v, want %s, %v", test.rate, limit, err, test.limit, test.err)
+		}
+	}
+}
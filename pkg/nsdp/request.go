@@ -1,14 +1,34 @@
 package nsdp
 
+import (
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
 // RequestMessages is a high-level API that sends messages via the
 // low-level Send API and returns the results as a slice of Messages.
-func RequestMessages(ifaceName string, request *Message, options ...Option) ([]Message, error) {
+// Like Send, it returns the responses of all devices that succeeded
+// along with OperationErrors if some devices report a failure.
+func RequestMessages(ifaceName string, request *Message, options ...Option) (responses []Message, err error) {
 	// Get operation options.
 	opts, err := GetDefaultOptions().Apply(options...)
 	if err != nil {
 		return nil, err
 	}
 
+	ctx, span := startSpan(opts.Context, opts, "nsdp.RequestMessages",
+		append(recordAttributes(request),
+			attribute.String("nsdp.interface", ifaceName),
+			attribute.String("nsdp.mac", opts.Selector.MAC.String()),
+			attribute.String("nsdp.ip", opts.Selector.IP.String()),
+		)...,
+	)
+	defer func() {
+		span.SetAttributes(attribute.Int("nsdp.responses", len(responses)))
+		endSpan(span, err)
+	}()
+
 	// Fetch network interface.
 	iface, err := GetInterface(ifaceName)
 	if err != nil {
@@ -25,31 +45,76 @@ func RequestMessages(ifaceName string, request *Message, options ...Option) ([]M
 	}
 
 	// Send message to broadcast address.
-	responses, err := Send(opts.Context, iface, opts.Selector.IP, request)
-	if err != nil {
+	opts.Logger.Debug("requesting messages",
+		"interface", iface.Name,
+		"mac", opts.Selector.MAC.String(),
+		"ip", opts.Selector.IP.String(),
+	)
+	responses, err = Send(ctx, iface, opts.Selector.IP, request,
+		WithRetryPolicy(opts.RetryPolicy),
+		WithResponseLimit(opts.ResponseLimit),
+		WithLogger(opts.Logger),
+		WithTracerProvider(opts.TracerProvider),
+	)
+
+	// Keep the responses of all other devices if some devices failed.
+	var opErrs OperationErrors
+	if err != nil && !errors.As(err, &opErrs) {
 		return nil, err
 	}
 
-	return responses, nil
+	return responses, err
 }
 
 // RequestDevices is a high-level API that sends messages via the high-level
-// RequestMessage API and returns the results as a slice of Devices.
+// RequestMessage API and returns the results as a slice of Devices. If some
+// devices report a failure, the other devices are returned along with
+// OperationErrors describing the failures.
 func RequestDevices(ifaceName string, request *Message, options ...Option) ([]Device, error) {
-	// Use the RequestMessage function to get the responses.
-	responses, err := RequestMessages(ifaceName, request, options...)
+	// Get operation options.
+	opts, err := GetDefaultOptions().Apply(options...)
 	if err != nil {
 		return nil, err
 	}
 
+	// Use the RequestMessage function to get the responses. If some
+	// devices failed, the error is returned after the other devices.
+	responses, requestErr := RequestMessages(ifaceName, request, options...)
+	var opErrs OperationErrors
+	if requestErr != nil && !errors.As(requestErr, &opErrs) {
+		return nil, requestErr
+	}
+
 	// Convert responses to devices.
 	devices := make([]Device, len(responses))
 	for i, response := range responses {
 		// This is safe because we previously allocated the slice.
 		if err := devices[i].UnmarshalMessage(&response); err != nil {
-			return nil, err
+			// Keep the device if only some of its records are malformed,
+			// such that a single faulty firmware does not fail a scan.
+			var decodeErrs DecodeErrors
+			if !errors.As(err, &decodeErrs) {
+				return nil, err
+			}
+
+			for _, decodeErr := range decodeErrs {
+				opts.Logger.Warn("failed to decode record",
+					"device", devices[i].MAC.String(),
+					"record", decodeErr.RecordType.ID.String(),
+					"len", decodeErr.Len,
+					"offset", decodeErr.Offset,
+				)
+			}
+		}
+
+		for id, value := range devices[i].Unknown {
+			opts.Logger.Warn("device returned unknown record type",
+				"device", devices[i].MAC.String(),
+				"record", id.String(),
+				"len", len(value),
+			)
 		}
 	}
 
-	return devices, nil
+	return devices, requestErr
 }
@@ -0,0 +1,9 @@
+package nsdp
+
+// Result describes the outcome of an operation for a single device.
+// If the device reported a failure, Err is set and the device only
+// contains the MAC and IP address of the device.
+type Result struct {
+	Device Device
+	Err    error
+}
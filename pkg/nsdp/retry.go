@@ -0,0 +1,80 @@
+package nsdp
+
+import (
+	"context"
+	"time"
+)
+
+// RetryPolicy defines how the attempts of an operation are scheduled.
+type RetryPolicy struct {
+	// Backoff is the delay before the first retry. It is
+	// multiplied by the multiplier before every further retry.
+	Backoff time.Duration
+	// MaxBackoff limits the delay between two attempts.
+	MaxBackoff time.Duration
+	// Multiplier is the factor by which the delay grows.
+	Multiplier float64
+	// QuietPeriod ends an attempt to reach all devices once no new
+	// device has responded for this duration. It also skips further
+	// retries if an attempt did not find any new devices. A value
+	// of zero waits for the full timeout of every attempt.
+	QuietPeriod time.Duration
+}
+
+// DefaultRetryPolicy is the retry policy used if no
+// retry policy is supplied for an operation.
+var DefaultRetryPolicy = RetryPolicy{
+	Backoff:    10 * time.Millisecond,
+	MaxBackoff: time.Second,
+	Multiplier: 2,
+}
+
+// Delay returns the delay before the given retry, where the first retry is 1.
+func (p *RetryPolicy) Delay(retry uint) time.Duration {
+	if retry == 0 || p.Backoff <= 0 {
+		return 0
+	}
+
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+
+	delay := float64(p.Backoff)
+	for i := uint(1); i < retry; i++ {
+		delay *= multiplier
+		if p.MaxBackoff > 0 && delay >= float64(p.MaxBackoff) {
+			return p.MaxBackoff
+		}
+	}
+
+	if p.MaxBackoff > 0 && time.Duration(delay) > p.MaxBackoff {
+		return p.MaxBackoff
+	}
+	return time.Duration(delay)
+}
+
+// Validate checks if the retry policy is valid.
+func (p *RetryPolicy) Validate() error {
+	if p.Backoff < 0 || p.MaxBackoff < 0 || p.QuietPeriod < 0 || p.Multiplier < 0 {
+		return ErrInvalidRetryPolicy
+	}
+	return nil
+}
+
+// sleep waits for the duration or until the context is done.
+func sleep(ctx context.Context, duration time.Duration) error {
+	if duration <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
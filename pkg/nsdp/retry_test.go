@@ -0,0 +1,74 @@
+package nsdp
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyDelay(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy RetryPolicy
+		delays []time.Duration
+	}{
+		{
+			name:   "default",
+			policy: DefaultRetryPolicy,
+			delays: []time.Duration{0, 10 * time.Millisecond, 20 * time.Millisecond, 40 * time.Millisecond, 80 * time.Millisecond},
+		},
+		{
+			name:   "capped",
+			policy: RetryPolicy{Backoff: 300 * time.Millisecond, MaxBackoff: time.Second, Multiplier: 3},
+			delays: []time.Duration{0, 300 * time.Millisecond, 900 * time.Millisecond, time.Second, time.Second},
+		},
+		{
+			name:   "constant",
+			policy: RetryPolicy{Backoff: 50 * time.Millisecond, Multiplier: 0.5},
+			delays: []time.Duration{0, 50 * time.Millisecond, 50 * time.Millisecond, 50 * time.Millisecond},
+		},
+		{
+			name:   "disabled",
+			policy: RetryPolicy{MaxBackoff: time.Second, Multiplier: 2},
+			delays: []time.Duration{0, 0, 0},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for retry, want := range test.delays {
+				if got := test.policy.Delay(uint(retry)); got != want {
+					t.Errorf("Delay(%d) = %s, want %s", retry, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestRetryPolicyDelayDoesNotOverflow(t *testing.T) {
+	policy := RetryPolicy{Backoff: time.Second, MaxBackoff: time.Minute, Multiplier: 10}
+	if got := policy.Delay(1000); got != time.Minute {
+		t.Errorf("Delay(1000) = %s, want %s", got, time.Minute)
+	}
+}
+
+func TestRetryPolicyValidate(t *testing.T) {
+	if err := DefaultRetryPolicy.Validate(); err != nil {
+		t.Errorf("expected default policy to be valid, got %v", err)
+	}
+
+	policy := RetryPolicy{Backoff: -time.Second}
+	if err := policy.Validate(); !errors.Is(err, ErrInvalidRetryPolicy) {
+		t.Errorf("expected error %v, got %v", ErrInvalidRetryPolicy, err)
+	}
+}
+
+func TestSleepCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := sleep(ctx, time.Hour); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected error %v, got %v", context.Canceled, err)
+	}
+}
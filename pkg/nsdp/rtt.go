@@ -0,0 +1,257 @@
+package nsdp
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// RTTSamples is the number of round-trip times kept per device.
+	RTTSamples = 32
+	// MinRTTSamples is the number of round-trip times required
+	// before the adaptive timeout is derived from them.
+	MinRTTSamples = 3
+	// MinAdaptiveTimeout is the lower bound of an adaptive timeout.
+	MinAdaptiveTimeout = 20 * time.Millisecond
+	// MaxAdaptiveTimeout is the upper bound of an adaptive timeout.
+	MaxAdaptiveTimeout = 5 * time.Second
+)
+
+// RTTEntry holds the most recent round-trip times of a device.
+type RTTEntry struct {
+	IP      net.IP          `json:"ip"`
+	Samples []time.Duration `json:"samples"`
+}
+
+// RTTCache keeps the round-trip times of devices by their MAC
+// address, such that timeouts can be derived from them.
+type RTTCache struct {
+	mutex    sync.Mutex
+	modified bool
+	Devices  map[string]*RTTEntry `json:"devices"`
+}
+
+// NewRTTCache returns an empty round-trip time cache.
+func NewRTTCache() *RTTCache {
+	return &RTTCache{
+		Devices: make(map[string]*RTTEntry),
+	}
+}
+
+// DefaultRTTCachePath returns the path of the round-trip
+// time cache in the cache directory of the user.
+func DefaultRTTCachePath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "netadm", "rtt.json"), nil
+}
+
+// LoadRTTCache reads a round-trip time cache from a file.
+// An empty cache is returned if the file does not exist.
+func LoadRTTCache(path string) (*RTTCache, error) {
+	cache := NewRTTCache()
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return cache, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, cache); err != nil {
+		return nil, err
+	}
+	if cache.Devices == nil {
+		cache.Devices = make(map[string]*RTTEntry)
+	}
+
+	return cache, nil
+}
+
+// Save writes the round-trip time cache to a file. The file
+// is replaced atomically to not corrupt it if a concurrent
+// invocation saves the cache at the same time.
+func (c *RTTCache) Save(path string) error {
+	c.mutex.Lock()
+	data, err := json.Marshal(c)
+	c.mutex.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(path), ".rtt-*.json")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(file.Name(), path)
+}
+
+// Observe records the round-trip time of a device.
+func (c *RTTCache) Observe(mac net.HardwareAddr, ip net.IP, rtt time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	entry := c.Devices[mac.String()]
+	if entry == nil {
+		entry = &RTTEntry{}
+		c.Devices[mac.String()] = entry
+	}
+
+	c.modified = true
+	entry.IP = ip
+	entry.Samples = append(entry.Samples, rtt)
+	if len(entry.Samples) > RTTSamples {
+		entry.Samples = entry.Samples[len(entry.Samples)-RTTSamples:]
+	}
+}
+
+// Modified returns true if round-trip times were
+// observed since the cache was created or loaded.
+func (c *RTTCache) Modified() bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.modified
+}
+
+// Percentile returns the given percentile of the round-trip times of the
+// device matched by the selector. It returns false if the device is not
+// known well enough or if the selector matches all devices.
+func (c *RTTCache) Percentile(selector *Selector, percentile float64) (time.Duration, bool) {
+	if selector.IsAll() {
+		return 0, false
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	entry := c.lookup(selector)
+	if entry == nil || len(entry.Samples) < MinRTTSamples {
+		return 0, false
+	}
+
+	samples := append([]time.Duration{}, entry.Samples...)
+	sort.Slice(samples, func(i, j int) bool {
+		return samples[i] < samples[j]
+	})
+
+	// Use the nearest-rank method.
+	rank := int(percentile/100*float64(len(samples))+0.5) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	if rank >= len(samples) {
+		rank = len(samples) - 1
+	}
+
+	return samples[rank], true
+}
+
+// Timeout derives the timeout of an attempt from the 95th percentile of
+// the round-trip times of the device. The timeout is doubled for every
+// retry. The fallback is returned if the device is not known.
+func (c *RTTCache) Timeout(selector *Selector, attempt uint, fallback time.Duration) time.Duration {
+	p95, ok := c.Percentile(selector, 95)
+	if !ok {
+		return fallback
+	}
+
+	timeout := 2 * p95
+	for i := uint(1); i < attempt && timeout < MaxAdaptiveTimeout; i++ {
+		timeout *= 2
+	}
+
+	if timeout < MinAdaptiveTimeout {
+		return MinAdaptiveTimeout
+	}
+	if timeout > MaxAdaptiveTimeout {
+		return MaxAdaptiveTimeout
+	}
+	return timeout
+}
+
+// lookup returns the entry of the device matched by the selector.
+func (c *RTTCache) lookup(selector *Selector) *RTTEntry {
+	if selector.MAC != nil && selector.MAC.String() != SelectorAll.MAC.String() {
+		return c.Devices[selector.MAC.String()]
+	}
+
+	for _, entry := range c.Devices {
+		if selector.IP != nil && entry.IP.Equal(*selector.IP) {
+			return entry
+		}
+	}
+	return nil
+}
+
+// rttCache holds the cache that receives the round-trip times measured by Send.
+var rttCache struct {
+	sync.Mutex
+	cache *RTTCache
+}
+
+// SetRTTCache configures the cache that receives the round-trip times
+// of all responses received via Send. It is also used to derive the
+// timeouts of operations with an adaptive timeout. Passing nil
+// disables the measurement.
+func SetRTTCache(cache *RTTCache) {
+	rttCache.Lock()
+	defer rttCache.Unlock()
+
+	rttCache.cache = cache
+}
+
+// getRTTCache returns the configured round-trip time cache.
+func getRTTCache() *RTTCache {
+	rttCache.Lock()
+	defer rttCache.Unlock()
+
+	return rttCache.cache
+}
+
+// observeRTT records a round-trip time if the measurement is enabled.
+func observeRTT(mac net.HardwareAddr, ip net.IP, rtt time.Duration) {
+	if cache := getRTTCache(); cache != nil {
+		cache.Observe(mac, ip, rtt)
+	}
+}
+
+// attemptTimeout returns the timeout of an attempt. If the adaptive
+// timeout is enabled, it is derived from the round-trip times of the
+// selected device and the configured timeout is used as a fallback.
+func (o *Options) attemptTimeout(selector *Selector, attempt uint) time.Duration {
+	if !o.AdaptiveTimeout {
+		return o.Timeout
+	}
+
+	cache := getRTTCache()
+	if cache == nil {
+		return o.Timeout
+	}
+
+	return cache.Timeout(selector, attempt, o.Timeout)
+}
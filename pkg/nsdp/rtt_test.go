@@ -0,0 +1,140 @@
+package nsdp
+
+import (
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newTestRTTCache returns a cache with the given round-trip times of a device.
+func newTestRTTCache(mac net.HardwareAddr, ip net.IP, samples ...time.Duration) *RTTCache {
+	cache := NewRTTCache()
+	for _, rtt := range samples {
+		cache.Observe(mac, ip, rtt)
+	}
+	return cache
+}
+
+func TestRTTCachePercentile(t *testing.T) {
+	mac := net.HardwareAddr{0x02, 0x00, 0x00, 0x00, 0x00, 0x01}
+	ip := net.IP{192, 0, 2, 1}
+
+	samples := make([]time.Duration, 0, 20)
+	for i := 20; i > 0; i-- {
+		samples = append(samples, time.Duration(i)*time.Millisecond)
+	}
+	cache := newTestRTTCache(mac, ip, samples...)
+
+	tests := []struct {
+		name       string
+		selector   *Selector
+		percentile float64
+		want       time.Duration
+		ok         bool
+	}{
+		{name: "median by MAC", selector: NewSelector().SetMAC(&mac), percentile: 50, want: 10 * time.Millisecond, ok: true},
+		{name: "p95 by IP", selector: NewSelector().SetIP(&ip), percentile: 95, want: 19 * time.Millisecond, ok: true},
+		{name: "minimum", selector: NewSelector().SetMAC(&mac), percentile: 0, want: time.Millisecond, ok: true},
+		{name: "maximum", selector: NewSelector().SetMAC(&mac), percentile: 100, want: 20 * time.Millisecond, ok: true},
+		{name: "all devices", selector: NewSelector(), percentile: 95},
+		{name: "unknown device", selector: NewSelector().SetIP(&net.IP{192, 0, 2, 2}), percentile: 95},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, ok := cache.Percentile(test.selector, test.percentile)
+			if got != test.want || ok != test.ok {
+				t.Errorf("Percentile() = %s, %t, want %s, %t", got, ok, test.want, test.ok)
+			}
+		})
+	}
+}
+
+func TestRTTCachePercentileMinSamples(t *testing.T) {
+	mac := net.HardwareAddr{0x02, 0x00, 0x00, 0x00, 0x00, 0x01}
+	cache := newTestRTTCache(mac, nil, time.Millisecond, time.Millisecond)
+
+	if _, ok := cache.Percentile(NewSelector().SetMAC(&mac), 95); ok {
+		t.Errorf("expected no percentile with less than %d samples", MinRTTSamples)
+	}
+}
+
+func TestRTTCacheTimeout(t *testing.T) {
+	mac := net.HardwareAddr{0x02, 0x00, 0x00, 0x00, 0x00, 0x01}
+	selector := NewSelector().SetMAC(&mac)
+	fallback := 100 * time.Millisecond
+
+	tests := []struct {
+		name    string
+		rtt     time.Duration
+		attempt uint
+		want    time.Duration
+	}{
+		{name: "first attempt", rtt: 15 * time.Millisecond, attempt: 1, want: 30 * time.Millisecond},
+		{name: "second attempt", rtt: 15 * time.Millisecond, attempt: 2, want: 60 * time.Millisecond},
+		{name: "third attempt", rtt: 15 * time.Millisecond, attempt: 3, want: 120 * time.Millisecond},
+		{name: "lower bound", rtt: time.Millisecond, attempt: 1, want: MinAdaptiveTimeout},
+		{name: "upper bound", rtt: 2 * time.Second, attempt: 2, want: MaxAdaptiveTimeout},
+		{name: "many attempts", rtt: 15 * time.Millisecond, attempt: 1000, want: MaxAdaptiveTimeout},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cache := newTestRTTCache(mac, nil, test.rtt, test.rtt, test.rtt)
+			if got := cache.Timeout(selector, test.attempt, fallback); got != test.want {
+				t.Errorf("Timeout() = %s, want %s", got, test.want)
+			}
+		})
+	}
+
+	if got := NewRTTCache().Timeout(selector, 1, fallback); got != fallback {
+		t.Errorf("expected fallback %s for unknown device, got %s", fallback, got)
+	}
+}
+
+func TestRTTCacheObserveKeepsRecentSamples(t *testing.T) {
+	mac := net.HardwareAddr{0x02, 0x00, 0x00, 0x00, 0x00, 0x01}
+	cache := NewRTTCache()
+	for i := 1; i <= RTTSamples+10; i++ {
+		cache.Observe(mac, nil, time.Duration(i))
+	}
+
+	samples := cache.Devices[mac.String()].Samples
+	if len(samples) != RTTSamples || samples[0] != 11 {
+		t.Errorf("expected the %d most recent samples, got %v", RTTSamples, samples)
+	}
+}
+
+func TestRTTCacheSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "netadm", "rtt.json")
+
+	cache, err := LoadRTTCache(path)
+	if err != nil {
+		t.Fatalf("failed to load missing cache: %v", err)
+	}
+	if len(cache.Devices) != 0 || cache.Modified() {
+		t.Fatal("expected empty and unmodified cache")
+	}
+
+	mac := net.HardwareAddr{0x02, 0x00, 0x00, 0x00, 0x00, 0x01}
+	ip := net.IP{192, 0, 2, 1}
+	cache.Observe(mac, ip, 5*time.Millisecond)
+	if !cache.Modified() {
+		t.Fatal("expected cache to be modified")
+	}
+
+	if err := cache.Save(path); err != nil {
+		t.Fatalf("failed to save cache: %v", err)
+	}
+
+	loaded, err := LoadRTTCache(path)
+	if err != nil {
+		t.Fatalf("failed to load cache: %v", err)
+	}
+
+	entry := loaded.Devices[mac.String()]
+	if entry == nil || !entry.IP.Equal(ip) || len(entry.Samples) != 1 || entry.Samples[0] != 5*time.Millisecond {
+		t.Errorf("unexpected entry after loading: %+v", entry)
+	}
+}
@@ -2,15 +2,45 @@ package nsdp
 
 import (
 	"context"
-	"fmt"
+	"encoding/hex"
 	"net"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // Send is a low-level API that allows it to send and receive messages directly.
 // It is recommended to set an explicit destination IP as otherwise the message
 // will be sent to the global broadcast address, which is often filtered out by
 // routers.
-func Send(ctx context.Context, iface *net.Interface, dst *net.IP, request *Message) ([]Message, error) {
+//
+// If some devices report a failure, Send returns the responses of all
+// other devices along with OperationErrors describing the failures, such
+// that a single failing device does not hide the responses of others.
+//
+// Send waits until the context is done unless a response limit is set, in
+// which case it returns as soon as enough devices responded, or a quiet
+// period is set in the retry policy, in which case it returns once no new
+// device has responded for the quiet period.
+func Send(ctx context.Context, iface *net.Interface, dst *net.IP, request *Message, options ...Option) (responses []Message, err error) {
+	// Get operation options.
+	opts, err := GetDefaultOptions().Apply(options...)
+	if err != nil {
+		return nil, err
+	}
+	logger := opts.Logger
+
+	ctx, span := startSpan(ctx, opts, "nsdp.Send",
+		append(recordAttributes(request),
+			attribute.Int("nsdp.sequence", int(request.Header.Sequence)),
+		)...,
+	)
+	defer func() {
+		span.SetAttributes(attribute.Int("nsdp.responses", len(responses)))
+		endSpan(span, err)
+	}()
+
 	// Create a UDP socket to listen for incoming packets.
 	socketAddr := net.UDPAddr{
 		IP:   net.IPv4(0, 0, 0, 0),
@@ -18,13 +48,32 @@ func Send(ctx context.Context, iface *net.Interface, dst *net.IP, request *Messa
 	}
 	socket, err := net.ListenUDP("udp", &socketAddr)
 	if err != nil {
+		logger.Debug("failed to bind socket", "address", socketAddr.String(), "error", err)
 		return nil, err
 	}
 	defer socket.Close()
+	logger.Debug("bound socket", "address", socket.LocalAddr().String(), "interface", iface.Name)
+
+	// Determine the local address for the packet capture.
+	localAddr := net.UDPAddr{
+		IP:   net.IPv4zero,
+		Port: ClientPort,
+	}
+	if ip, err := GetInterfaceIPv4(iface); err == nil {
+		localAddr.IP = *ip
+	}
 
-	responses := make([]Message, 0)
+	// The responses and failures are collected by the receiving goroutine.
+	var mutex sync.Mutex
+	received := make([]Message, 0)
+	failures := make(OperationErrors, 0)
+	seen := make(map[string]bool)
+	var sentAt time.Time
 	errs := make(chan error, 1)
 
+	// The receiving goroutine signals whenever a new device responded.
+	progress := make(chan int, 1)
+
 	// Create a goroutine to listen for incoming packets.
 	go func() {
 		for {
@@ -34,37 +83,88 @@ func Send(ctx context.Context, iface *net.Interface, dst *net.IP, request *Messa
 			default:
 				buf := make([]byte, 1500)
 
-				n, err := socket.Read(buf)
+				n, remoteAddr, err := socket.ReadFromUDP(buf)
 				if err != nil {
 					errs <- err
 					return
 				}
+				capturePacket(logger, remoteAddr, &localAddr, buf[:n])
+				logger.Log(ctx, LevelTrace, "received message", "source", remoteAddr.String(), "hex", hex.EncodeToString(buf[:n]))
 
+				// Ignore malformed messages instead of failing the
+				// responses of all other devices.
 				response := new(Message)
 				if err := response.UnmarshalBinary(buf[:n]); err != nil {
 					if response.Header.Result == 0 {
-						errs <- ErrInvalidResponse
-						return
+						logger.Warn("ignoring malformed message", "source", remoteAddr.String(), "error", err)
+						continue
 					}
 				}
 
+				// Ignore requests of other clients, which may
+				// also be sent to the port of this client.
+				if response.Header.Operation != ReadResponse && response.Header.Operation != WriteResponse {
+					logger.Debug("ignoring packet", "source", remoteAddr.String(), "operation", response.Header.Operation.String())
+					continue
+				}
+
+				// Ignore responses to the requests of other clients or to
+				// previous requests, as they neither count towards the
+				// response limit nor yield a valid round-trip time.
+				if response.Header.ClientMAC != request.Header.ClientMAC || response.Header.Sequence != request.Header.Sequence {
+					logger.Debug("ignoring unrelated response",
+						"source", remoteAddr.String(),
+						"client", net.HardwareAddr(response.Header.ClientMAC[:]).String(),
+						"sequence", response.Header.Sequence,
+					)
+					continue
+				}
+
+				logger.Debug("received response",
+					"source", remoteAddr.String(),
+					"operation", response.Header.Operation.String(),
+					"sequence", response.Header.Sequence,
+					"result", ResponseCode(response.Header.Result).String(),
+					"records", len(response.Records),
+				)
+
 				// Check operation result status code.
 				// I assume all non-zero values are bad.
+				mutex.Lock()
 				if response.Header.Result != 0 {
-					switch response.Header.Result {
-					case uint16(ResponseCodeInvalidRecordLength):
-						errs <- ErrInvalidRecordLength
-					case uint16(ResponseCodeInvalidPassword):
-						errs <- ErrInvalidPassword
-					case uint16(ResponseCodeInvalidPasswordLockdown):
-						errs <- ErrInvalidPasswordLockdown
+					code := ResponseCode(response.Header.Result)
+					failures = append(failures, &OperationError{
+						MAC:       net.HardwareAddr(response.Header.ServerMAC[:]),
+						IP:        remoteAddr.IP,
+						Operation: request.Header.Operation,
+						Code:      code,
+						Record:    response.Header.Record,
+						Err:       code.Err(),
+					})
+				} else {
+					received = append(received, *response)
+				}
+				mac := net.HardwareAddr(response.Header.ServerMAC[:])
+				isNew := !seen[mac.String()]
+				seen[mac.String()] = true
+				devices := len(seen)
+				start := sentAt
+				mutex.Unlock()
+
+				if isNew {
+					// A response that arrives before the request was sent
+					// completely does not yield a valid round-trip time.
+					if !start.IsZero() {
+						observeRTT(mac, remoteAddr.IP, time.Since(start))
+					}
+
+					// Replace a pending signal with the latest count.
+					select {
+					case <-progress:
 					default:
-						errs <- fmt.Errorf("operation failed with status code 0x%04X", response.Header.Result)
 					}
-					return
+					progress <- devices
 				}
-
-				responses = append(responses, *response)
 			}
 		}
 	}()
@@ -83,14 +183,55 @@ func Send(ctx context.Context, iface *net.Interface, dst *net.IP, request *Messa
 	if dst != nil {
 		deviceAddr.IP = *dst
 	}
+	span.SetAttributes(attribute.String("nsdp.destination", deviceAddr.String()))
+	capturePacket(logger, &localAddr, &deviceAddr, payload)
+	logger.Debug("sending request",
+		"destination", deviceAddr.String(),
+		"operation", request.Header.Operation.String(),
+		"sequence", request.Header.Sequence,
+		"records", len(request.Records),
+	)
+	logger.Log(ctx, LevelTrace, "sending message", "destination", deviceAddr.String(), "hex", hex.EncodeToString(payload))
+	mutex.Lock()
+	sentAt = time.Now()
+	mutex.Unlock()
 	if _, err := socket.WriteToUDP(payload, &deviceAddr); err != nil {
+		logger.Debug("failed to send request", "destination", deviceAddr.String(), "error", err)
 		return nil, err
 	}
 
-	select {
-	case <-ctx.Done():
-		return responses, nil
-	case err := <-errs:
-		return nil, err
+	// Wait for the timeout, the response limit or the quiet period.
+	var quiet <-chan time.Time
+wait:
+	for {
+		select {
+		case <-ctx.Done():
+			break wait
+		case err := <-errs:
+			logger.Debug("request failed", "error", err)
+			return nil, err
+		case devices := <-progress:
+			if opts.ResponseLimit > 0 && uint(devices) >= opts.ResponseLimit {
+				logger.Debug("response limit reached", "devices", devices)
+				break wait
+			}
+			if opts.RetryPolicy.QuietPeriod > 0 {
+				quiet = time.After(opts.RetryPolicy.QuietPeriod)
+			}
+		case <-quiet:
+			logger.Debug("quiet period elapsed", "quiet_period", opts.RetryPolicy.QuietPeriod)
+			break wait
+		}
 	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	responses = append(make([]Message, 0, len(received)), received...)
+	logger.Debug("request completed", "responses", len(responses), "failures", len(failures))
+	if len(failures) > 0 {
+		return responses, append(OperationErrors{}, failures...)
+	}
+
+	return responses, nil
 }
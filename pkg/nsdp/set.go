@@ -3,48 +3,76 @@ package nsdp
 import (
 	"context"
 	"fmt"
-	"net"
+
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // Set provides a simplified way to set configuration keys on devices.
 func Set(id string, values map[string]string, options ...Option) ([]Device, error) {
+	// Check if all keys are valid.
+	records := make([]Record, 0, len(values))
+	for key, value := range values {
+		// Check if key is valid.
+		rt := RecordTypeByName[key]
+		if rt == nil {
+			return nil, fmt.Errorf(`unknown configuration key "%s"`, key)
+		}
+
+		records = append(records, NewRecord(rt, []byte(value)))
+	}
+
+	return SetRecords(id, records, options...)
+}
+
+// SetRecords provides a way to write already encoded records to a device.
+// It takes care of authenticating the request with the password provided
+// via the options. The write is sent exactly once and is not retried.
+func SetRecords(id string, records []Record, options ...Option) (devices []Device, err error) {
 	// Get operation options.
 	opts, err := GetDefaultOptions().Apply(options...)
 	if err != nil {
 		return nil, err
 	}
 
-	selector := NewSelector()
-	// Allow usage of keyword "all" to select all devices.
-	if id != "all" {
-		// Check if the device is identified via its IP address.
-		ip := net.ParseIP(id)
-		if ip == nil {
-			// Fall back to MAC address device identification.
-			mac, err := net.ParseMAC(id)
-			if err != nil {
-				return nil, ErrInvalidDeviceIdentifier
-			}
-			selector.SetMAC(&mac)
-		} else {
-			selector.SetIP(&ip)
-		}
-	}
+	spanCtx, span := startSpan(opts.Context, opts, "nsdp.Set",
+		append(recordAttributes(&Message{Records: records}), attribute.String("nsdp.device", id))...,
+	)
+	defer func() {
+		span.SetAttributes(attribute.Int("nsdp.devices", len(devices)))
+		endSpan(span, err)
+	}()
+	options = append(options, WithContext(spanCtx))
 
-	// Check if all keys are valid.
-	for key := range values {
-		// Check if key is valid.
-		if RecordTypeByName[key] == nil {
-			return nil, fmt.Errorf(`unknown configuration key "%s"`, key)
-		}
+	selector, err := ParseSelector(id)
+	if err != nil {
+		return nil, err
 	}
 
 	// Prepare password for authentication.
-	devices, err := Get(id, []string{"mac", "ip", "passwordencryption"}, options...)
+	devices, err = Get(id, []string{"mac", "ip", "passwordencryption", "model", "firmware"}, options...)
 	if err != nil {
 		return nil, err
 	}
+	span.SetAttributes(attribute.String("nsdp.mac", devices[0].MAC.String()))
+
+	// Reject records that are not supported by the model of the device.
+	if c := LookupCapability(devices[0].Model, devices[0].Firmware); c != nil {
+		for _, record := range records {
+			if rt := record.Type(); rt != nil {
+				if err := c.Check(rt); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	// Fall back to the encryption mode of the model if the device did not report one.
 	encryptionMode := devices[0].PasswordEncryption
+	if encryptionMode == EncryptionModeNone {
+		if c := LookupCapability(devices[0].Model, devices[0].Firmware); c != nil {
+			encryptionMode = c.PasswordEncryption
+		}
+	}
 	id = devices[0].IP.String()
 
 	nonce := make([]byte, 4)
@@ -70,54 +98,54 @@ func Set(id string, values map[string]string, options ...Option) ([]Device, erro
 	request := NewMessage(WriteRequest)
 
 	if encryptionMode == EncryptionModeNone || encryptionMode == EncryptionModeSimple {
-		request.Records = append(request.Records, Record{
-			ID:    RecordPassword.ID,
-			Value: encryptedPassword,
-			Len:   uint16(len(encryptedPassword)),
-		})
+		request.Records = append(request.Records, NewRecord(RecordPassword, encryptedPassword))
 	}
 
 	if encryptionMode == EncryptionModeHash32 || encryptionMode == EncryptionModeHash64 {
-		request.Records = append(request.Records,
-			Record{
-				ID:    RecordPasswordHash.ID,
-				Value: encryptedPassword,
-				Len:   uint16(len(encryptedPassword)),
-			},
-		)
+		request.Records = append(request.Records, NewRecord(RecordPasswordHash, encryptedPassword))
 	}
 
 	// Add request records.
-	for key, value := range values {
-		encodedValue := []byte(value)
+	request.Records = append(request.Records, records...)
 
-		request.Records = append(request.Records, Record{
-			ID:    RecordTypeByName[key].ID,
-			Value: encodedValue,
-			Len:   uint16(len(encodedValue)),
-		})
+	ids := make([]string, len(records))
+	for i, record := range records {
+		ids[i] = record.ID.String()
 	}
+	opts.Logger.Debug("writing records", "device", id, "records", ids, "encryption", encryptionMode.String())
 
 	// Create context to handle timeout.
-	ctx, cancel := context.WithTimeout(context.Background(), opts.Timeout)
+	ctx, cancel := context.WithTimeout(spanCtx, opts.attemptTimeout(selector, 1))
 	defer cancel()
 
+	// Return as soon as the selected device confirmed the write.
+	limit := uint(0)
+	if !selector.IsAll() {
+		limit = 1
+	}
+
 	// Run scan for devices.
 	devs, err := RequestDevices(opts.InterfaceName, request,
 		WithContext(ctx),
 		WithSelector(selector),
+		WithRetryPolicy(opts.RetryPolicy),
+		WithResponseLimit(limit),
+		WithLogger(opts.Logger),
+		WithTracerProvider(opts.TracerProvider),
 	)
+	// The selector matches only the written device, so OperationErrors
+	// describe its failure and there are no responses of other devices.
 	if err != nil {
+		setAttempt(err, 1)
 		return nil, err
 	}
 
-	// Deduplicate results from all attempts.
-	devices = DeduplicateDevices(devices, devs)
-
-	// Check if any devices were found.
-	if len(devices) == 0 {
-		return nil, ErrNoDevicesFound
+	// The write is never repeated, as the device may have applied it
+	// without confirming it. The reads before the write already primed
+	// the ARP cache of the device, so it is expected to respond.
+	if len(devs) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrWriteUnconfirmed, id)
 	}
 
-	return devices, nil
+	return DeduplicateDevices(devices, devs), nil
 }
@@ -0,0 +1,103 @@
+package nsdp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName is the name of the tracer that creates the spans of this library.
+const tracerName = "github.com/nicklasfrahm/netadm/pkg/nsdp"
+
+// startSpan starts a new span with the tracer provider of the options.
+func startSpan(ctx context.Context, opts *Options, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return opts.TracerProvider.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// endSpan ends a span and records the error if the operation failed.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		span.SetAttributes(attribute.String("error.type", errorType(err)))
+	}
+	span.End()
+}
+
+// errorTypes maps the sentinel errors that operations commonly
+// fail with to fixed names, which are used as the error type.
+var errorTypes = []struct {
+	err  error
+	name string
+}{
+	{context.DeadlineExceeded, "context.DeadlineExceeded"},
+	{context.Canceled, "context.Canceled"},
+	{ErrNoDevicesFound, "nsdp.ErrNoDevicesFound"},
+	{ErrMultipleDevices, "nsdp.ErrMultipleDevices"},
+	{ErrInvalidDeviceIdentifier, "nsdp.ErrInvalidDeviceIdentifier"},
+	{ErrInterfaceDown, "nsdp.ErrInterfaceDown"},
+	{ErrInvalidInterfaceAddress, "nsdp.ErrInvalidInterfaceAddress"},
+	{ErrInvalidResponse, "nsdp.ErrInvalidResponse"},
+	{ErrInvalidEndOfMessage, "nsdp.ErrInvalidEndOfMessage"},
+	{ErrInvalidRecordLength, "nsdp.ErrInvalidRecordLength"},
+	{ErrUnsupportedRecord, "nsdp.ErrUnsupportedRecord"},
+	{ErrUnsupportedKey, "nsdp.ErrUnsupportedKey"},
+	{ErrInvalidRecordValue, "nsdp.ErrInvalidRecordValue"},
+	{ErrOperationFailed, "nsdp.ErrOperationFailed"},
+	{ErrInvalidPassword, "nsdp.ErrInvalidPassword"},
+	{ErrInvalidPasswordLockdown, "nsdp.ErrInvalidPasswordLockdown"},
+	{ErrFailedNonceRetrieval, "nsdp.ErrFailedNonceRetrieval"},
+	{ErrWriteUnconfirmed, "nsdp.ErrWriteUnconfirmed"},
+}
+
+// errorType returns a low-cardinality description of an error. Known
+// sentinel errors are mapped to fixed names, while all other errors are
+// described by their type, as their messages may contain addresses or
+// other variable values.
+func errorType(err error) string {
+	var decodeErrs DecodeErrors
+	if errors.As(err, &decodeErrs) {
+		return "nsdp.DecodeErrors"
+	}
+
+	// Multiple failures may wrap different sentinel errors.
+	var opErrs OperationErrors
+	if errors.As(err, &opErrs) && len(opErrs) > 1 {
+		return "nsdp.OperationErrors"
+	}
+
+	for _, errorType := range errorTypes {
+		if errors.Is(err, errorType.err) {
+			return errorType.name
+		}
+	}
+
+	// Skip the wrappers created by fmt.Errorf, as their type does not
+	// describe the error, and use the type of the error they wrap.
+	for {
+		name := fmt.Sprintf("%T", err)
+		inner := errors.Unwrap(err)
+		if inner == nil || !strings.HasPrefix(name, "*fmt.wrap") {
+			return name
+		}
+		err = inner
+	}
+}
+
+// recordAttributes describes the operation and the record IDs of a message.
+func recordAttributes(msg *Message) []attribute.KeyValue {
+	ids := make([]string, len(msg.Records))
+	for i, record := range msg.Records {
+		ids[i] = record.ID.String()
+	}
+
+	return []attribute.KeyValue{
+		attribute.String("nsdp.operation", msg.Header.Operation.String()),
+		attribute.StringSlice("nsdp.records", ids),
+	}
+}
@@ -0,0 +1,37 @@
+package nsdp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+)
+
+func TestErrorType(t *testing.T) {
+	opErr := &OperationError{Code: ResponseCode(0x0007), Err: ErrInvalidPassword}
+
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{name: "sentinel", err: ErrNoDevicesFound, want: "nsdp.ErrNoDevicesFound"},
+		{name: "wrapped sentinel", err: fmt.Errorf("device 192.0.2.1: %w", ErrInvalidRecordLength), want: "nsdp.ErrInvalidRecordLength"},
+		{name: "deadline", err: fmt.Errorf("read: %w", context.DeadlineExceeded), want: "context.DeadlineExceeded"},
+		{name: "operation error", err: opErr, want: "nsdp.ErrInvalidPassword"},
+		{name: "operation errors", err: OperationErrors{opErr, opErr}, want: "nsdp.OperationErrors"},
+		{name: "decode errors", err: DecodeErrors{&DecodeError{}}, want: "nsdp.DecodeErrors"},
+		{name: "network error", err: &net.OpError{Op: "read", Net: "udp", Err: errors.New("connection refused")}, want: "*net.OpError"},
+		{name: "plain error", err: errors.New("failed"), want: "*errors.errorString"},
+		{name: "wrapped error", err: fmt.Errorf("listen on 192.0.2.1:63321: %w", &net.AddrError{Err: "bad", Addr: "x"}), want: "*net.AddrError"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := errorType(test.err); got != test.want {
+				t.Errorf("expected %q, got %q", test.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,395 @@
+package nsdp
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sort"
+)
+
+const (
+	// VLANIDMin is the lowest valid VLAN ID.
+	VLANIDMin = 1
+	// VLANIDMax is the highest VLAN ID supported by the devices.
+	VLANIDMax = 4093
+)
+
+// VLAN802QTable describes the 802.1Q VLAN configuration of a device.
+// It keeps track of all changes, such that they can be validated and
+// written to the device in a single request.
+type VLAN802QTable struct {
+	Engine    VLANEngine
+	PortCount uint8
+	// HostPort is the port the management host is connected to. All
+	// changes that would make the device unreachable via this port are
+	// rejected. It must be set to change PVIDs or untagged members, as
+	// the port can't be detected via NSDP.
+	HostPort uint8
+
+	vlans           map[uint16]*VLAN802Q
+	pvids           map[uint8]uint16
+	initialPVIDs    map[uint8]uint16
+	initialUntagged map[uint16][]uint8
+	changed         map[uint16]bool
+	changedPVIDs    map[uint8]bool
+	deleted         map[uint16]bool
+}
+
+// NewVLAN802QTable creates a VLAN table from the configuration of a device.
+func NewVLAN802QTable(device *Device) *VLAN802QTable {
+	t := &VLAN802QTable{
+		Engine:          device.VLANEngine,
+		PortCount:       device.PortCount,
+		vlans:           make(map[uint16]*VLAN802Q),
+		pvids:           make(map[uint8]uint16),
+		initialPVIDs:    make(map[uint8]uint16),
+		initialUntagged: make(map[uint16][]uint8),
+		changed:         make(map[uint16]bool),
+		changedPVIDs:    make(map[uint8]bool),
+		deleted:         make(map[uint16]bool),
+	}
+
+	for _, vlan := range device.VLANs802Q {
+		// Copy the VLAN to not modify the device.
+		v := VLAN802Q{
+			ID:       vlan.ID,
+			Tagged:   append([]uint8{}, vlan.Tagged...),
+			Untagged: append([]uint8{}, vlan.Untagged...),
+		}
+		t.vlans[v.ID] = &v
+		t.initialUntagged[v.ID] = append([]uint8{}, vlan.Untagged...)
+	}
+
+	for _, pvid := range device.PVIDs {
+		t.pvids[pvid.ID] = pvid.PVID
+		t.initialPVIDs[pvid.ID] = pvid.PVID
+	}
+
+	return t
+}
+
+// GetVLAN802QTable reads the 802.1Q VLAN configuration of a single device.
+func GetVLAN802QTable(id string, options ...Option) (*VLAN802QTable, error) {
+	device, err := getDevice(id, []string{"mac", "portcount", "vlanengine", "vlans802q", "pvids"}, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewVLAN802QTable(device), nil
+}
+
+// SetVLAN802QTable validates the VLAN table and writes all of its
+// pending changes to a device in a single request.
+func SetVLAN802QTable(id string, table *VLAN802QTable, options ...Option) ([]Device, error) {
+	if err := table.Validate(); err != nil {
+		return nil, err
+	}
+
+	return SetRecords(id, table.Records(), options...)
+}
+
+// VLANs returns all VLANs sorted by their ID.
+func (t *VLAN802QTable) VLANs() []VLAN802Q {
+	vlans := make([]VLAN802Q, 0, len(t.vlans))
+	for _, vlan := range t.vlans {
+		vlans = append(vlans, *vlan)
+	}
+
+	sort.Slice(vlans, func(i, j int) bool {
+		return vlans[i].ID < vlans[j].ID
+	})
+
+	return vlans
+}
+
+// PVIDs returns the PVIDs of all ports sorted by port.
+func (t *VLAN802QTable) PVIDs() []PVID {
+	pvids := make([]PVID, 0, len(t.pvids))
+	for port, pvid := range t.pvids {
+		pvids = append(pvids, PVID{ID: port, PVID: pvid})
+	}
+
+	sort.Slice(pvids, func(i, j int) bool {
+		return pvids[i].ID < pvids[j].ID
+	})
+
+	return pvids
+}
+
+// Add creates a new VLAN with the given member ports.
+func (t *VLAN802QTable) Add(id uint16, tagged []uint8, untagged []uint8) error {
+	if err := t.checkEngine(); err != nil {
+		return err
+	}
+
+	if err := checkVLANID(id); err != nil {
+		return err
+	}
+
+	if _, ok := t.vlans[id]; ok {
+		return fmt.Errorf("%w: %d", ErrVLANExists, id)
+	}
+
+	t.vlans[id] = &VLAN802Q{ID: id, Tagged: []uint8{}, Untagged: []uint8{}}
+	t.changed[id] = true
+	delete(t.deleted, id)
+
+	return t.SetMembers(id, tagged, untagged)
+}
+
+// Delete removes a VLAN. A VLAN can't be deleted while it is the PVID of a port.
+func (t *VLAN802QTable) Delete(id uint16) error {
+	if err := t.checkEngine(); err != nil {
+		return err
+	}
+
+	if _, ok := t.vlans[id]; !ok {
+		return fmt.Errorf("%w: %d", ErrVLANNotFound, id)
+	}
+
+	for _, pvid := range t.PVIDs() {
+		if pvid.PVID == id {
+			return fmt.Errorf("%w %d: VLAN %d is its PVID", ErrStrandedPort, pvid.ID, id)
+		}
+	}
+
+	delete(t.vlans, id)
+	delete(t.changed, id)
+	t.deleted[id] = true
+
+	return nil
+}
+
+// SetMembers adds the given ports to a VLAN. Ports that already are members
+// of the VLAN are moved to the requested group of tagged or untagged ports.
+// With the basic 802.1Q engine a port can only be an untagged member of a
+// single VLAN, so the port is removed from its previous VLAN and its PVID
+// is updated accordingly.
+func (t *VLAN802QTable) SetMembers(id uint16, tagged []uint8, untagged []uint8) error {
+	if err := t.checkEngine(); err != nil {
+		return err
+	}
+
+	vlan, ok := t.vlans[id]
+	if !ok {
+		return fmt.Errorf("%w: %d", ErrVLANNotFound, id)
+	}
+
+	if err := t.checkPorts(append(append([]uint8{}, tagged...), untagged...)); err != nil {
+		return err
+	}
+
+	if t.Engine == VLANEngine802QBasic && len(tagged) > 0 {
+		return fmt.Errorf("%w: tagged ports require the %s engine", ErrUnsupportedVLANEngine, VLANEngine802QAdvanced)
+	}
+
+	if t.Engine == VLANEngine802QBasic {
+		for _, port := range untagged {
+			for _, other := range t.vlans {
+				if other.ID != id && containsPort(other.Untagged, port) {
+					other.Untagged = excludePorts(other.Untagged, []uint8{port})
+					t.changed[other.ID] = true
+				}
+			}
+			t.setPVID(port, id)
+		}
+	}
+
+	vlan.Tagged = sortPorts(append(excludePorts(vlan.Tagged, untagged), excludePorts(tagged, vlan.Tagged)...))
+	vlan.Untagged = sortPorts(append(excludePorts(vlan.Untagged, tagged), excludePorts(untagged, vlan.Untagged)...))
+	t.changed[id] = true
+
+	return nil
+}
+
+// RemoveMembers removes the given ports from a VLAN.
+func (t *VLAN802QTable) RemoveMembers(id uint16, ports []uint8) error {
+	if err := t.checkEngine(); err != nil {
+		return err
+	}
+
+	vlan, ok := t.vlans[id]
+	if !ok {
+		return fmt.Errorf("%w: %d", ErrVLANNotFound, id)
+	}
+
+	if err := t.checkPorts(ports); err != nil {
+		return err
+	}
+
+	vlan.Tagged = excludePorts(vlan.Tagged, ports)
+	vlan.Untagged = excludePorts(vlan.Untagged, ports)
+	t.changed[id] = true
+
+	return nil
+}
+
+// SetPVID assigns the given VLAN as PVID to the ports.
+func (t *VLAN802QTable) SetPVID(id uint16, ports []uint8) error {
+	if err := t.checkEngine(); err != nil {
+		return err
+	}
+
+	if _, ok := t.vlans[id]; !ok {
+		return fmt.Errorf("%w: %d", ErrVLANNotFound, id)
+	}
+
+	if err := t.checkPorts(ports); err != nil {
+		return err
+	}
+
+	for _, port := range ports {
+		t.setPVID(port, id)
+	}
+
+	return nil
+}
+
+// ChangesAccess returns true if the pending changes alter the PVID of a
+// port or remove a port from the untagged members of a VLAN. These are
+// the changes that may make the device unreachable.
+func (t *VLAN802QTable) ChangesAccess() bool {
+	for port, initial := range t.initialPVIDs {
+		if t.pvids[port] != initial {
+			return true
+		}
+	}
+
+	for id, untagged := range t.initialUntagged {
+		vlan, ok := t.vlans[id]
+		if !ok || len(excludePorts(untagged, vlan.Untagged)) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Validate checks that every port remains a member of its PVID VLAN and
+// that the port of the management host stays an untagged member of its
+// original PVID VLAN. Otherwise the changes could make a port or even
+// the whole device unreachable. Changes of the access of ports require
+// the port of the management host to be set.
+func (t *VLAN802QTable) Validate() error {
+	for _, pvid := range t.PVIDs() {
+		vlan, ok := t.vlans[pvid.PVID]
+		if !ok || !(containsPort(vlan.Tagged, pvid.ID) || containsPort(vlan.Untagged, pvid.ID)) {
+			return fmt.Errorf("%w %d: it must remain a member of its PVID VLAN %d", ErrStrandedPort, pvid.ID, pvid.PVID)
+		}
+	}
+
+	if t.HostPort == 0 {
+		if t.ChangesAccess() {
+			return fmt.Errorf("%w: changing PVIDs or untagged members could lock out the management host", ErrHostPortRequired)
+		}
+		return nil
+	}
+
+	if err := t.checkPorts([]uint8{t.HostPort}); err != nil {
+		return err
+	}
+
+	initial, ok := t.initialPVIDs[t.HostPort]
+	if !ok {
+		return nil
+	}
+
+	if t.pvids[t.HostPort] != initial {
+		return fmt.Errorf("%w %d: the PVID of the management host port must remain %d", ErrStrandedPort, t.HostPort, initial)
+	}
+
+	if !containsPort(t.vlans[initial].Untagged, t.HostPort) {
+		return fmt.Errorf("%w %d: the management host port must remain an untagged member of VLAN %d", ErrStrandedPort, t.HostPort, initial)
+	}
+
+	return nil
+}
+
+// Records encodes all pending changes into records. The VLANs are
+// written before the PVIDs, as a PVID must refer to an existing VLAN.
+func (t *VLAN802QTable) Records() []Record {
+	records := make([]Record, 0)
+
+	for _, vlan := range t.VLANs() {
+		if t.changed[vlan.ID] {
+			records = append(records, vlan.Record(t.PortCount))
+		}
+	}
+
+	for _, pvid := range t.PVIDs() {
+		if t.changedPVIDs[pvid.ID] {
+			records = append(records, pvid.Record())
+		}
+	}
+
+	return append(records, deleteRecords(t.deleted)...)
+}
+
+// deleteRecords encodes the deletion of the VLANs into records sorted by ID.
+func deleteRecords(deleted map[uint16]bool) []Record {
+	ids := make([]uint16, 0, len(deleted))
+	for id := range deleted {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i] < ids[j]
+	})
+
+	records := make([]Record, len(ids))
+	for i, id := range ids {
+		records[i] = NewRecord(RecordVLANDelete, binary.BigEndian.AppendUint16(nil, id))
+	}
+	return records
+}
+
+// checkVLANID ensures that the VLAN ID is within the valid range.
+func checkVLANID(id uint16) error {
+	if id < VLANIDMin || id > VLANIDMax {
+		return fmt.Errorf("%w %d: must be between %d and %d", ErrInvalidVLANID, id, VLANIDMin, VLANIDMax)
+	}
+	return nil
+}
+
+// setPVID assigns the VLAN as PVID to the port and marks it as changed.
+func (t *VLAN802QTable) setPVID(port uint8, id uint16) {
+	if t.pvids[port] != id {
+		t.pvids[port] = id
+		t.changedPVIDs[port] = true
+	}
+}
+
+// checkEngine ensures that an 802.1Q VLAN engine is active.
+func (t *VLAN802QTable) checkEngine() error {
+	if t.Engine != VLANEngine802QBasic && t.Engine != VLANEngine802QAdvanced {
+		return fmt.Errorf("%w: %s", ErrUnsupportedVLANEngine, t.Engine)
+	}
+	return nil
+}
+
+// checkPorts ensures that all ports exist on the device.
+func (t *VLAN802QTable) checkPorts(ports []uint8) error {
+	return checkPorts(ports, t.PortCount)
+}
+
+// checkPorts ensures that all ports exist on a device with the given number
+// of ports. An unknown number of ports is an error, as the ports can't be
+// validated without it.
+func checkPorts(ports []uint8, portCount uint8) error {
+	if portCount == 0 {
+		return fmt.Errorf("%w: device did not report its port count", ErrInvalidPort)
+	}
+
+	for _, port := range ports {
+		if port == 0 || port > portCount {
+			return fmt.Errorf("%w %d: device has %d ports", ErrInvalidPort, port, portCount)
+		}
+	}
+	return nil
+}
+
+// sortPorts sorts a slice of ports in ascending order.
+func sortPorts(ports []uint8) []uint8 {
+	sort.Slice(ports, func(i, j int) bool {
+		return ports[i] < ports[j]
+	})
+	return ports
+}
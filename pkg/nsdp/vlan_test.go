@@ -0,0 +1,172 @@
+package nsdp
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestVLAN802QTableAdd(t *testing.T) {
+	tests := []struct {
+		name string
+		id   uint16
+		err  error
+	}{
+		{name: "lowest ID", id: VLANIDMin},
+		{name: "highest ID", id: VLANIDMax},
+		{name: "zero ID", id: 0, err: ErrInvalidVLANID},
+		{name: "reserved ID", id: VLANIDMax + 1, err: ErrInvalidVLANID},
+		{name: "existing ID", id: 10, err: ErrVLANExists},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			table := NewVLAN802QTable(&Device{
+				VLANEngine: VLANEngine802QAdvanced,
+				PortCount:  8,
+				VLANs802Q:  []VLAN802Q{{ID: 10, Untagged: []uint8{1, 2}}},
+			})
+
+			err := table.Add(test.id, []uint8{3}, nil)
+			if !errors.Is(err, test.err) {
+				t.Fatalf("expected error %v, got %v", test.err, err)
+			}
+		})
+	}
+}
+
+func TestVLANPortTableAdd(t *testing.T) {
+	tests := []struct {
+		name string
+		id   uint16
+		err  error
+	}{
+		{name: "lowest ID", id: VLANIDMin},
+		{name: "highest ID", id: VLANIDMax},
+		{name: "zero ID", id: 0, err: ErrInvalidVLANID},
+		{name: "reserved ID", id: VLANIDMax + 1, err: ErrInvalidVLANID},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			table := NewVLANPortTable(&Device{
+				VLANEngine: VLANEnginePortBasic,
+				PortCount:  8,
+			})
+
+			err := table.Add(test.id, []uint8{1})
+			if !errors.Is(err, test.err) {
+				t.Fatalf("expected error %v, got %v", test.err, err)
+			}
+		})
+	}
+}
+
+func TestVLAN802QTableValidateHostPort(t *testing.T) {
+	tests := []struct {
+		name     string
+		hostPort uint8
+		update   func(table *VLAN802QTable) error
+		err      error
+	}{
+		{name: "new VLAN", update: func(table *VLAN802QTable) error { return table.Add(30, []uint8{3}, nil) }},
+		{name: "PVID change without host port", update: func(table *VLAN802QTable) error { return table.SetPVID(20, []uint8{3}) }, err: ErrHostPortRequired},
+		{name: "PVID change with host port", hostPort: 1, update: func(table *VLAN802QTable) error { return table.SetPVID(20, []uint8{3}) }},
+		{name: "untagged removal without host port", update: func(table *VLAN802QTable) error { return table.RemoveMembers(20, []uint8{5}) }, err: ErrHostPortRequired},
+		{name: "PVID change of host port", hostPort: 1, update: func(table *VLAN802QTable) error { return table.SetPVID(20, []uint8{1}) }, err: ErrStrandedPort},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			table := NewVLAN802QTable(&Device{
+				VLANEngine: VLANEngine802QAdvanced,
+				PortCount:  8,
+				VLANs802Q: []VLAN802Q{
+					{ID: 10, Tagged: []uint8{5}, Untagged: []uint8{1, 2, 3}},
+					{ID: 20, Tagged: []uint8{1, 3}, Untagged: []uint8{4, 5}},
+				},
+				PVIDs: []PVID{{ID: 1, PVID: 10}, {ID: 2, PVID: 10}, {ID: 3, PVID: 10}, {ID: 4, PVID: 20}, {ID: 5, PVID: 10}},
+			})
+			table.HostPort = test.hostPort
+
+			if err := test.update(table); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := table.Validate(); !errors.Is(err, test.err) {
+				t.Fatalf("expected error %v, got %v", test.err, err)
+			}
+		})
+	}
+}
+
+func TestVLANPortTableValidateHostPort(t *testing.T) {
+	tests := []struct {
+		name     string
+		hostPort uint8
+		remove   []uint8
+		err      error
+	}{
+		{name: "no removal"},
+		{name: "removal without host port", remove: []uint8{2}, err: ErrHostPortRequired},
+		{name: "removal with host port", hostPort: 1, remove: []uint8{2}},
+		{name: "removal of host port", hostPort: 1, remove: []uint8{1}, err: ErrStrandedPort},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			table := NewVLANPortTable(&Device{
+				VLANEngine: VLANEnginePortAdvanced,
+				PortCount:  2,
+				VLANsPort:  []VLANPort{{ID: 1, Ports: []uint8{1, 2}}, {ID: 2, Ports: []uint8{2}}},
+			})
+			table.HostPort = test.hostPort
+
+			if err := table.RemoveMembers(1, test.remove); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := table.Validate(); !errors.Is(err, test.err) {
+				t.Fatalf("expected error %v, got %v", test.err, err)
+			}
+		})
+	}
+}
+
+func TestCheckPorts(t *testing.T) {
+	tests := []struct {
+		name      string
+		ports     []uint8
+		portCount uint8
+		err       error
+	}{
+		{name: "valid ports", ports: []uint8{1, 8}, portCount: 8},
+		{name: "port 0", ports: []uint8{0}, portCount: 8, err: ErrInvalidPort},
+		{name: "port out of range", ports: []uint8{9}, portCount: 8, err: ErrInvalidPort},
+		{name: "unknown port count", ports: []uint8{1}, err: ErrInvalidPort},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := checkPorts(test.ports, test.portCount); !errors.Is(err, test.err) {
+				t.Fatalf("expected error %v, got %v", test.err, err)
+			}
+		})
+	}
+}
+
+func TestVLANPortTableValidateMaxPorts(t *testing.T) {
+	ports := make([]uint8, 255)
+	for i := range ports {
+		ports[i] = uint8(i + 1)
+	}
+
+	table := NewVLANPortTable(&Device{
+		VLANEngine: VLANEnginePortAdvanced,
+		PortCount:  255,
+		VLANsPort:  []VLANPort{{ID: 1, Ports: ports}},
+	})
+
+	if err := table.Validate(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
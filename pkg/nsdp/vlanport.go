@@ -0,0 +1,253 @@
+package nsdp
+
+import (
+	"fmt"
+	"sort"
+)
+
+// VLANPortTable describes the port-based VLAN configuration of a device.
+// It keeps track of all changes, such that they can be validated and
+// written to the device in a single request.
+type VLANPortTable struct {
+	Engine    VLANEngine
+	PortCount uint8
+	// HostPort is the port the management host is connected to. It must
+	// remain a member of all of its VLANs. It must be set to remove any
+	// member of a VLAN, as the port can't be detected via NSDP.
+	HostPort uint8
+
+	vlans        map[uint16]*VLANPort
+	initialPorts map[uint16][]uint8
+	changed      map[uint16]bool
+	deleted      map[uint16]bool
+}
+
+// NewVLANPortTable creates a VLAN table from the configuration of a device.
+func NewVLANPortTable(device *Device) *VLANPortTable {
+	t := &VLANPortTable{
+		Engine:       device.VLANEngine,
+		PortCount:    device.PortCount,
+		vlans:        make(map[uint16]*VLANPort),
+		initialPorts: make(map[uint16][]uint8),
+		changed:      make(map[uint16]bool),
+		deleted:      make(map[uint16]bool),
+	}
+
+	for _, vlan := range device.VLANsPort {
+		// Copy the VLAN to not modify the device.
+		v := VLANPort{
+			ID:    vlan.ID,
+			Ports: append([]uint8{}, vlan.Ports...),
+		}
+		t.vlans[v.ID] = &v
+		t.initialPorts[v.ID] = append([]uint8{}, vlan.Ports...)
+	}
+
+	return t
+}
+
+// GetVLANPortTable reads the port-based VLAN configuration of a single device.
+func GetVLANPortTable(id string, options ...Option) (*VLANPortTable, error) {
+	device, err := getDevice(id, []string{"mac", "portcount", "vlanengine", "vlansport"}, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewVLANPortTable(device), nil
+}
+
+// SetVLANPortTable validates the VLAN table and writes all of its
+// pending changes to a device in a single request.
+func SetVLANPortTable(id string, table *VLANPortTable, options ...Option) ([]Device, error) {
+	if err := table.Validate(); err != nil {
+		return nil, err
+	}
+
+	return SetRecords(id, table.Records(), options...)
+}
+
+// SetVLANEngine changes the VLAN engine of a device. Please note
+// that the device deletes all existing VLANs and resets the PVIDs
+// of all ports if the VLAN engine is changed.
+func SetVLANEngine(id string, engine VLANEngine, options ...Option) ([]Device, error) {
+	return SetRecords(id, []Record{engine.Record()}, options...)
+}
+
+// VLANs returns all VLANs sorted by their ID.
+func (t *VLANPortTable) VLANs() []VLANPort {
+	vlans := make([]VLANPort, 0, len(t.vlans))
+	for _, vlan := range t.vlans {
+		vlans = append(vlans, *vlan)
+	}
+
+	sort.Slice(vlans, func(i, j int) bool {
+		return vlans[i].ID < vlans[j].ID
+	})
+
+	return vlans
+}
+
+// Add creates a new VLAN with the given member ports.
+func (t *VLANPortTable) Add(id uint16, ports []uint8) error {
+	if err := t.checkEngine(); err != nil {
+		return err
+	}
+
+	if err := checkVLANID(id); err != nil {
+		return err
+	}
+
+	if _, ok := t.vlans[id]; ok {
+		return fmt.Errorf("%w: %d", ErrVLANExists, id)
+	}
+
+	t.vlans[id] = &VLANPort{ID: id, Ports: []uint8{}}
+	t.changed[id] = true
+	delete(t.deleted, id)
+
+	return t.AddMembers(id, ports)
+}
+
+// Delete removes a VLAN.
+func (t *VLANPortTable) Delete(id uint16) error {
+	if err := t.checkEngine(); err != nil {
+		return err
+	}
+
+	if _, ok := t.vlans[id]; !ok {
+		return fmt.Errorf("%w: %d", ErrVLANNotFound, id)
+	}
+
+	delete(t.vlans, id)
+	delete(t.changed, id)
+	t.deleted[id] = true
+
+	return nil
+}
+
+// AddMembers adds the given ports to a VLAN. With the basic port-based
+// engine a port can only be a member of a single VLAN, so the port is
+// removed from its previous VLAN.
+func (t *VLANPortTable) AddMembers(id uint16, ports []uint8) error {
+	if err := t.checkEngine(); err != nil {
+		return err
+	}
+
+	vlan, ok := t.vlans[id]
+	if !ok {
+		return fmt.Errorf("%w: %d", ErrVLANNotFound, id)
+	}
+
+	if err := checkPorts(ports, t.PortCount); err != nil {
+		return err
+	}
+
+	if t.Engine == VLANEnginePortBasic {
+		for _, other := range t.vlans {
+			if other.ID != id && len(excludePorts(other.Ports, ports)) != len(other.Ports) {
+				other.Ports = excludePorts(other.Ports, ports)
+				t.changed[other.ID] = true
+			}
+		}
+	}
+
+	vlan.Ports = sortPorts(append(vlan.Ports, excludePorts(ports, vlan.Ports)...))
+	t.changed[id] = true
+
+	return nil
+}
+
+// RemoveMembers removes the given ports from a VLAN.
+func (t *VLANPortTable) RemoveMembers(id uint16, ports []uint8) error {
+	if err := t.checkEngine(); err != nil {
+		return err
+	}
+
+	vlan, ok := t.vlans[id]
+	if !ok {
+		return fmt.Errorf("%w: %d", ErrVLANNotFound, id)
+	}
+
+	if err := checkPorts(ports, t.PortCount); err != nil {
+		return err
+	}
+
+	vlan.Ports = excludePorts(vlan.Ports, ports)
+	t.changed[id] = true
+
+	return nil
+}
+
+// ChangesAccess returns true if the pending changes remove a port from
+// a VLAN. These are the changes that may make the device unreachable.
+func (t *VLANPortTable) ChangesAccess() bool {
+	for id, ports := range t.initialPorts {
+		vlan, ok := t.vlans[id]
+		if !ok || len(excludePorts(ports, vlan.Ports)) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Validate checks that every port remains a member of at least one
+// VLAN, as a port without any VLAN can't forward any traffic, and that
+// the port of the management host remains a member of all of its VLANs.
+// Removing members requires the port of the management host to be set.
+func (t *VLANPortTable) Validate() error {
+	for port := 1; port <= int(t.PortCount); port++ {
+		member := false
+		for _, vlan := range t.vlans {
+			if containsPort(vlan.Ports, uint8(port)) {
+				member = true
+				break
+			}
+		}
+
+		if !member {
+			return fmt.Errorf("%w %d: it must remain a member of at least one VLAN", ErrStrandedPort, port)
+		}
+	}
+
+	if t.HostPort == 0 {
+		if t.ChangesAccess() {
+			return fmt.Errorf("%w: removing members could lock out the management host", ErrHostPortRequired)
+		}
+		return nil
+	}
+
+	if err := checkPorts([]uint8{t.HostPort}, t.PortCount); err != nil {
+		return err
+	}
+
+	for id, ports := range t.initialPorts {
+		vlan, ok := t.vlans[id]
+		if containsPort(ports, t.HostPort) && (!ok || !containsPort(vlan.Ports, t.HostPort)) {
+			return fmt.Errorf("%w %d: the management host port must remain a member of VLAN %d", ErrStrandedPort, t.HostPort, id)
+		}
+	}
+
+	return nil
+}
+
+// Records encodes all pending changes into records.
+func (t *VLANPortTable) Records() []Record {
+	records := make([]Record, 0)
+
+	for _, vlan := range t.VLANs() {
+		if t.changed[vlan.ID] {
+			records = append(records, vlan.Record(t.PortCount))
+		}
+	}
+
+	return append(records, deleteRecords(t.deleted)...)
+}
+
+// checkEngine ensures that a port-based VLAN engine is active.
+func (t *VLANPortTable) checkEngine() error {
+	if t.Engine != VLANEnginePortBasic && t.Engine != VLANEnginePortAdvanced {
+		return fmt.Errorf("%w: %s", ErrUnsupportedVLANEngine, t.Engine)
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package nsdp
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// warmup sends a lightweight read request for the MAC address of the
+// selected devices and returns true if any device responded. A device
+// that first needs to look up the MAC address of the host via ARP does
+// not respond to the first request it receives. Priming the ARP cache
+// of the device with this read allows the actual operation to succeed
+// without blindly sending every request twice.
+func warmup(ctx context.Context, opts *Options, selector *Selector, limit uint) (primed bool, err error) {
+	ctx, span := startSpan(ctx, opts, "nsdp.Warmup")
+	defer func() {
+		span.SetAttributes(attribute.Bool("nsdp.primed", primed))
+		endSpan(span, err)
+	}()
+
+	request := NewMessage(ReadRequest)
+	request.Records = append(request.Records, Record{ID: RecordMAC.ID})
+
+	// Create context to handle timeout.
+	ctx, cancel := context.WithTimeout(ctx, opts.attemptTimeout(selector, 1))
+	defer cancel()
+
+	opts.Logger.Debug("sending priming read", "mac", selector.MAC.String(), "ip", selector.IP.String())
+	responses, err := RequestMessages(opts.InterfaceName, request,
+		WithContext(ctx),
+		WithSelector(selector),
+		WithRetryPolicy(opts.RetryPolicy),
+		WithResponseLimit(limit),
+		WithLogger(opts.Logger),
+		WithTracerProvider(opts.TracerProvider),
+	)
+
+	// A device that reports a failure is reachable as well.
+	var opErrs OperationErrors
+	if errors.As(err, &opErrs) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return len(responses) > 0, nil
+}
@@ -0,0 +1,255 @@
+// Package pcap reads and writes UDP datagrams in the pcap file format,
+// such that captures can be opened with tools like Wireshark.
+package pcap
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+const (
+	// magicMicroseconds identifies a pcap file with microsecond timestamps.
+	magicMicroseconds = 0xA1B2C3D4
+	// magicNanoseconds identifies a pcap file with nanosecond timestamps.
+	magicNanoseconds = 0xA1B23C4D
+	// snapLen is the maximum length of a captured packet.
+	snapLen = 65535
+	// maxSnapLen is the largest snapshot length accepted when reading,
+	// which matches the limit of tcpdump. It prevents a corrupt capture
+	// from causing huge allocations.
+	maxSnapLen = 262144
+)
+
+// LinkType describes the link-layer header of the packets in a capture.
+type LinkType uint32
+
+const (
+	// LinkTypeEthernet identifies packets with an Ethernet header.
+	LinkTypeEthernet LinkType = 1
+	// LinkTypeRaw identifies packets that start with an IP header.
+	LinkTypeRaw LinkType = 101
+)
+
+var (
+	// ErrInvalidFormat is returned if a file is not a pcap file.
+	ErrInvalidFormat = errors.New("invalid pcap file format")
+	// ErrUnsupportedLinkType is returned if the link type of a capture is not supported.
+	ErrUnsupportedLinkType = errors.New("unsupported link type")
+	// ErrPacketTooLarge is returned if a packet exceeds the snapshot length of a capture.
+	ErrPacketTooLarge = errors.New("packet exceeds snapshot length")
+)
+
+// Packet describes a captured UDP datagram.
+type Packet struct {
+	Time    time.Time
+	Src     *net.UDPAddr
+	Dst     *net.UDPAddr
+	Payload []byte
+}
+
+// Writer writes UDP datagrams with IPv4 and UDP headers into a pcap file.
+// It is safe for concurrent use.
+type Writer struct {
+	mutex sync.Mutex
+	w     io.Writer
+}
+
+// NewWriter creates a new Writer and writes the pcap file header.
+func NewWriter(w io.Writer) (*Writer, error) {
+	header := make([]byte, 24)
+	binary.LittleEndian.PutUint32(header[0:4], magicMicroseconds)
+	binary.LittleEndian.PutUint16(header[4:6], 2)
+	binary.LittleEndian.PutUint16(header[6:8], 4)
+	binary.LittleEndian.PutUint32(header[16:20], snapLen)
+	binary.LittleEndian.PutUint32(header[20:24], uint32(LinkTypeRaw))
+
+	if _, err := w.Write(header); err != nil {
+		return nil, err
+	}
+
+	return &Writer{w: w}, nil
+}
+
+// WritePacket writes a UDP datagram including its IPv4 and UDP headers.
+func (w *Writer) WritePacket(packet Packet) error {
+	data := make([]byte, 28+len(packet.Payload))
+
+	// Encode the IPv4 header.
+	ip := data[0:20]
+	ip[0] = 0x45
+	binary.BigEndian.PutUint16(ip[2:4], uint16(len(data)))
+	ip[8] = 64
+	ip[9] = 17
+	copy(ip[12:16], packet.Src.IP.To4())
+	copy(ip[16:20], packet.Dst.IP.To4())
+	binary.BigEndian.PutUint16(ip[10:12], checksum(ip))
+
+	// Encode the UDP header. A checksum of zero is
+	// valid for IPv4 and means it is not computed.
+	udp := data[20:28]
+	binary.BigEndian.PutUint16(udp[0:2], uint16(packet.Src.Port))
+	binary.BigEndian.PutUint16(udp[2:4], uint16(packet.Dst.Port))
+	binary.BigEndian.PutUint16(udp[4:6], uint16(8+len(packet.Payload)))
+	copy(data[28:], packet.Payload)
+
+	// Encode the packet header.
+	header := make([]byte, 16)
+	binary.LittleEndian.PutUint32(header[0:4], uint32(packet.Time.Unix()))
+	binary.LittleEndian.PutUint32(header[4:8], uint32(packet.Time.Nanosecond()/1000))
+	binary.LittleEndian.PutUint32(header[8:12], uint32(len(data)))
+	binary.LittleEndian.PutUint32(header[12:16], uint32(len(data)))
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if _, err := w.w.Write(header); err != nil {
+		return err
+	}
+	_, err := w.w.Write(data)
+	return err
+}
+
+// Reader reads UDP datagrams from a pcap file. Packets that
+// are not IPv4 UDP datagrams are skipped.
+type Reader struct {
+	r        io.Reader
+	order    binary.ByteOrder
+	nanos    bool
+	snapLen  uint32
+	linkType LinkType
+}
+
+// NewReader creates a new Reader and reads the pcap file header.
+func NewReader(r io.Reader) (*Reader, error) {
+	header := make([]byte, 24)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, ErrInvalidFormat
+	}
+
+	reader := &Reader{r: r}
+	for _, order := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		switch order.Uint32(header[0:4]) {
+		case magicMicroseconds:
+			reader.order = order
+		case magicNanoseconds:
+			reader.order = order
+			reader.nanos = true
+		}
+	}
+	if reader.order == nil {
+		return nil, ErrInvalidFormat
+	}
+
+	reader.snapLen = reader.order.Uint32(header[16:20])
+	if reader.snapLen == 0 || reader.snapLen > maxSnapLen {
+		reader.snapLen = maxSnapLen
+	}
+
+	reader.linkType = LinkType(reader.order.Uint32(header[20:24]))
+	if reader.linkType != LinkTypeEthernet && reader.linkType != LinkTypeRaw {
+		return nil, ErrUnsupportedLinkType
+	}
+
+	return reader, nil
+}
+
+// ReadPacket reads the next UDP datagram. It returns io.EOF
+// once all packets of the capture are read.
+func (r *Reader) ReadPacket() (*Packet, error) {
+	for {
+		header := make([]byte, 16)
+		if _, err := io.ReadFull(r.r, header); err != nil {
+			if errors.Is(err, io.ErrUnexpectedEOF) {
+				return nil, ErrInvalidFormat
+			}
+			return nil, err
+		}
+
+		// Validate the length before allocating the buffer, as
+		// a corrupt capture may contain an arbitrary length.
+		length := r.order.Uint32(header[8:12])
+		if length > r.snapLen {
+			return nil, ErrPacketTooLarge
+		}
+
+		data := make([]byte, length)
+		if _, err := io.ReadFull(r.r, data); err != nil {
+			return nil, ErrInvalidFormat
+		}
+
+		fraction := time.Duration(r.order.Uint32(header[4:8]))
+		if !r.nanos {
+			fraction *= time.Microsecond
+		}
+		timestamp := time.Unix(int64(r.order.Uint32(header[0:4])), int64(fraction))
+
+		if packet := r.decode(data); packet != nil {
+			packet.Time = timestamp
+			return packet, nil
+		}
+	}
+}
+
+// decode extracts the UDP datagram from a packet. It
+// returns nil if the packet is not a IPv4 UDP datagram.
+func (r *Reader) decode(data []byte) *Packet {
+	if r.linkType == LinkTypeEthernet {
+		if len(data) < 14 {
+			return nil
+		}
+
+		// Skip the Ethernet header and an optional VLAN tag.
+		etherType := binary.BigEndian.Uint16(data[12:14])
+		data = data[14:]
+		if etherType == 0x8100 && len(data) >= 4 {
+			etherType = binary.BigEndian.Uint16(data[2:4])
+			data = data[4:]
+		}
+		if etherType != 0x0800 {
+			return nil
+		}
+	}
+
+	if len(data) < 20 || data[0]>>4 != 4 || data[9] != 17 {
+		return nil
+	}
+
+	headerLen := int(data[0]&0x0F) * 4
+	if len(data) < headerLen+8 {
+		return nil
+	}
+	udp := data[headerLen:]
+
+	length := int(binary.BigEndian.Uint16(udp[4:6]))
+	if length < 8 || length > len(udp) {
+		length = len(udp)
+	}
+
+	return &Packet{
+		Src: &net.UDPAddr{
+			IP:   net.IP(append([]byte{}, data[12:16]...)),
+			Port: int(binary.BigEndian.Uint16(udp[0:2])),
+		},
+		Dst: &net.UDPAddr{
+			IP:   net.IP(append([]byte{}, data[16:20]...)),
+			Port: int(binary.BigEndian.Uint16(udp[2:4])),
+		},
+		Payload: append([]byte{}, udp[8:length]...),
+	}
+}
+
+// checksum calculates the internet checksum of a header.
+func checksum(header []byte) uint16 {
+	var sum uint32
+	for i := 0; i+1 < len(header); i += 2 {
+		sum += uint32(binary.BigEndian.Uint16(header[i : i+2]))
+	}
+	for sum > 0xFFFF {
+		sum = (sum >> 16) + (sum & 0xFFFF)
+	}
+	return ^uint16(sum)
+}
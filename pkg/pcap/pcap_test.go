@@ -0,0 +1,89 @@
+package pcap
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestRoundTrip(t *testing.T) {
+	packets := []Packet{
+		{
+			Time:    time.Unix(1700000000, 123000),
+			Src:     &net.UDPAddr{IP: net.IPv4(192, 0, 2, 1), Port: 63321},
+			Dst:     &net.UDPAddr{IP: net.IPv4(255, 255, 255, 255), Port: 63322},
+			Payload: []byte{0x01, 0x01, 0x00, 0x00},
+		},
+		{
+			Time:    time.Unix(1700000001, 0),
+			Src:     &net.UDPAddr{IP: net.IPv4(192, 0, 2, 2), Port: 63322},
+			Dst:     &net.UDPAddr{IP: net.IPv4(192, 0, 2, 1), Port: 63321},
+			Payload: []byte{},
+		},
+	}
+
+	var buf bytes.Buffer
+	writer, err := NewWriter(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, packet := range packets {
+		if err := writer.WritePacket(packet); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	reader, err := NewReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range packets {
+		got, err := reader.ReadPacket()
+		if err != nil {
+			t.Fatalf("packet %d: %v", i, err)
+		}
+		if !got.Time.Equal(want.Time) {
+			t.Errorf("packet %d: expected time %v, got %v", i, want.Time, got.Time)
+		}
+		if got.Src.String() != want.Src.String() || got.Dst.String() != want.Dst.String() {
+			t.Errorf("packet %d: expected %s -> %s, got %s -> %s", i, want.Src, want.Dst, got.Src, got.Dst)
+		}
+		if !bytes.Equal(got.Payload, want.Payload) {
+			t.Errorf("packet %d: expected payload %X, got %X", i, want.Payload, got.Payload)
+		}
+	}
+
+	if _, err := reader.ReadPacket(); !errors.Is(err, io.EOF) {
+		t.Errorf("expected %v, got %v", io.EOF, err)
+	}
+}
+
+func TestReadPacketTooLarge(t *testing.T) {
+	var buf bytes.Buffer
+	if _, err := NewWriter(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	header := make([]byte, 16)
+	binary.LittleEndian.PutUint32(header[8:12], 0xFFFFFFFF)
+	binary.LittleEndian.PutUint32(header[12:16], 0xFFFFFFFF)
+	buf.Write(header)
+
+	reader, err := NewReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := reader.ReadPacket(); !errors.Is(err, ErrPacketTooLarge) {
+		t.Errorf("expected %v, got %v", ErrPacketTooLarge, err)
+	}
+}
+
+func TestNewReaderInvalidFormat(t *testing.T) {
+	if _, err := NewReader(bytes.NewReader(make([]byte, 24))); !errors.Is(err, ErrInvalidFormat) {
+		t.Errorf("expected %v, got %v", ErrInvalidFormat, err)
+	}
+}